@@ -0,0 +1,22 @@
+package valuegraph
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// address returns the line with the address of v for its label, per Config.ShowAddresses, or
+// an empty string if it's not to be shown.
+func (g *Graph) address(v reflect.Value) string {
+	if !g.cfg.ShowAddresses {
+		return ""
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Chan:
+		if v.IsNil() {
+			return ""
+		}
+		return fmt.Sprintf(`\n%#x`, v.Pointer())
+	}
+	return ""
+}
@@ -0,0 +1,133 @@
+package valuegraph
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// An AmountType recognizes decimal or money types, so that their values are graphed as the
+// amount they hold instead of by walking into their internals.
+type AmountType struct {
+	// Name of the shape of types recognized, for documentation.
+	Name string
+	// Match reports whether values of type t are amounts of this kind. It is typically a
+	// check of the method set of t and *t, so that the package defining the type doesn't need
+	// to be imported.
+	Match func(t reflect.Type) bool
+	// Format returns the amount held by v, whose type was matched. Use CallString to call its
+	// methods.
+	Format func(v reflect.Value) (string, error)
+}
+
+// CommonAmounts recognizes common community decimal and money types by their methods:
+//
+//   - github.com/shopspring/decimal.Decimal and types like it, with methods String() string
+//     and Exponent() int32.
+//   - github.com/Rhymond/go-money.Money and types like it, with methods Display() string
+//     and Amount() int64.
+//   - github.com/cockroachdb/apd.Decimal and types like it, with methods Text(byte) string
+//     and IsZero() bool.
+//
+// To recognize more types, set Config.Amounts to a new slice with these and your own.
+var CommonAmounts = []AmountType{{
+	Name: "shopspring/decimal",
+	Match: func(t reflect.Type) bool {
+		return hasMethod(t, "String", nil, stringType) && hasMethod(t, "Exponent", nil, reflect.TypeOf(int32(0)))
+	},
+	Format: func(v reflect.Value) (string, error) {
+		return CallString(v, "String")
+	},
+}, {
+	Name: "Rhymond/go-money",
+	Match: func(t reflect.Type) bool {
+		return hasMethod(t, "Display", nil, stringType) && hasMethod(t, "Amount", nil, reflect.TypeOf(int64(0)))
+	},
+	Format: func(v reflect.Value) (string, error) {
+		return CallString(v, "Display")
+	},
+}, {
+	Name: "cockroachdb/apd",
+	Match: func(t reflect.Type) bool {
+		return hasMethod(t, "Text", reflect.TypeOf(byte(0)), stringType) && hasMethod(t, "IsZero", nil, reflect.TypeOf(false))
+	},
+	Format: func(v reflect.Value) (string, error) {
+		return CallString(v, "Text", byte('f'))
+	},
+}}
+
+var stringType = reflect.TypeOf("")
+
+// hasMethod reports whether t or *t has a method with the given name, taking in (if not nil)
+// and returning out.
+func hasMethod(t reflect.Type, name string, in, out reflect.Type) bool {
+	if t.Kind() != reflect.Ptr {
+		t = reflect.PtrTo(t)
+	}
+	m, ok := t.MethodByName(name)
+	if !ok {
+		return false
+	}
+	// The receiver is the first argument.
+	mt := m.Type
+	if in == nil && mt.NumIn() != 1 || in != nil && (mt.NumIn() != 2 || mt.In(1) != in) {
+		return false
+	}
+	return mt.NumOut() == 1 && mt.Out(0) == out
+}
+
+// CallString calls the method of v with the given name and arguments, which returns a string,
+// even if it has a pointer receiver and v isn't addressable. A panic in the method is returned
+// as an error.
+func CallString(v reflect.Value, name string, args ...interface{}) (s string, err error) {
+	m := v.MethodByName(name)
+	if !m.IsValid() && v.Kind() != reflect.Ptr {
+		if !v.CanAddr() {
+			c := reflect.New(v.Type()).Elem()
+			c.Set(v)
+			v = c
+		}
+		m = v.Addr().MethodByName(name)
+	}
+	if !m.IsValid() {
+		return "", fmt.Errorf("%v has no method %v", v.Type(), name)
+	}
+	in := make([]reflect.Value, len(args))
+	for i, a := range args {
+		in[i] = reflect.ValueOf(a)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v.%v panicked: %v", v.Type(), name, r)
+		}
+	}()
+	return m.Call(in)[0].String(), nil
+}
+
+// amount formats v per Config.Amounts, if its type is recognized.
+func (g *Graph) amount(v reflect.Value) (string, bool) {
+	if len(g.cfg.Amounts) == 0 || v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		return "", false
+	}
+	at, ok := g.amountTypes[v.Type()]
+	if !ok {
+		for i := range g.cfg.Amounts {
+			if g.cfg.Amounts[i].Match(v.Type()) {
+				at = &g.cfg.Amounts[i]
+				break
+			}
+		}
+		g.amountTypes[v.Type()] = at
+	}
+	if at == nil {
+		return "", false
+	}
+	x, ok := g.exposed(v)
+	if !ok {
+		return "", false
+	}
+	s, err := at.Format(x)
+	if err != nil {
+		return "\n(" + err.Error() + ")", true
+	}
+	return "\n" + s, true
+}
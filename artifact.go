@@ -0,0 +1,75 @@
+package valuegraph
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tcard/valuegraph/artifactspec"
+)
+
+// Artifact returns the graph as an artifact for external tools, as described in package
+// artifactspec. Positions set with Pin are included as the view's pins.
+func (g *Graph) Artifact() *artifactspec.Artifact {
+	a := &artifactspec.Artifact{
+		Version:  artifactspec.Version,
+		Metadata: map[string]string{"generator": "valuegraph"},
+		Nodes:    []artifactspec.Node{},
+		Edges:    []artifactspec.Edge{},
+		Dot:      g.Dot(),
+	}
+	pins := map[string]artifactspec.Position{}
+	for _, n := range g.Graph.Nodes.Sorted() {
+		node := artifactspec.Node{ID: n.Name, Attrs: map[string]string{}}
+		for k, v := range n.Attrs {
+			v = unquote(v)
+			switch k {
+			case "label":
+				node.Label = v
+			case "tooltip":
+				node.Path = v
+			case "pos":
+				var p artifactspec.Position
+				if _, err := fmt.Sscanf(v, "%g,%g!", &p.X, &p.Y); err == nil && strings.HasSuffix(v, "!") {
+					pins[n.Name] = p
+				}
+			default:
+				node.Attrs[string(k)] = v
+			}
+		}
+		a.Nodes = append(a.Nodes, node)
+	}
+	for _, e := range g.Graph.Edges.Edges {
+		edge := artifactspec.Edge{From: e.Src, To: e.Dst, Attrs: map[string]string{}}
+		for k, v := range e.Attrs {
+			edge.Attrs[string(k)] = unquote(v)
+		}
+		a.Edges = append(a.Edges, edge)
+	}
+	if len(pins) > 0 {
+		a.View = &artifactspec.View{Pins: pins}
+	}
+	return a
+}
+
+// unquote undoes quote, also turning \n line breaks in labels into newlines.
+func unquote(s string) string {
+	if len(s) < 2 || s[0] != '"' || s[len(s)-1] != '"' {
+		return s
+	}
+	s = s[1 : len(s)-1]
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n', 'l', 'r':
+			b.WriteByte('\n')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
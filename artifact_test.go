@@ -0,0 +1,23 @@
+package valuegraph
+
+import "testing"
+
+func TestUnquote(t *testing.T) {
+	for _, tc := range []struct {
+		s, want string
+	}{
+		{`""`, ""},
+		{`"plain"`, "plain"},
+		{`plain`, "plain"},
+		{`"`, `"`},
+		{`"open`, `"open`},
+		{`"a \"b\" \\ c"`, `a "b" \ c`},
+		{`"one\ntwo\lthree\rfour"`, "one\ntwo\nthree\nfour"},
+		{`"trailing\"`, `trailing\`},
+		{`"other \x escape"`, "other x escape"},
+	} {
+		if got := unquote(tc.s); got != tc.want {
+			t.Errorf("unquote(%q) = %q, want %q", tc.s, got, tc.want)
+		}
+	}
+}
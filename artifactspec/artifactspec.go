@@ -0,0 +1,163 @@
+// Package artifactspec defines a JSON artifact describing a graph made by valuegraph, stable
+// enough for tools that don't run Go, like editor extensions, to render it.
+//
+// The artifact format is versioned. Within a version, fields are only ever added, never removed
+// or changed in meaning, so readers should ignore fields they don't know.
+package artifactspec
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Version is the version of the artifact format described by this package.
+const Version = 1
+
+// An Artifact is a graph plus the state needed to display it as its author did.
+type Artifact struct {
+	// Version of the format, see Version.
+	Version int `json:"version"`
+	// Free-form information about how the artifact was made, like the type of the graphed value.
+	Metadata map[string]string `json:"metadata,omitempty"`
+	Nodes    []Node            `json:"nodes"`
+	Edges    []Edge            `json:"edges"`
+	View     *View             `json:"view,omitempty"`
+	// The graph in dot format, for readers that delegate layout to Graphviz.
+	Dot string `json:"dot,omitempty"`
+}
+
+// A Node of the graph.
+type Node struct {
+	ID string `json:"id"`
+	// Text of the node, with lines separated by "\n".
+	Label string `json:"label"`
+	// Path to the value from the graphed value, if the node represents a value.
+	Path string `json:"path,omitempty"`
+	// Any other Graphviz attributes, unquoted.
+	Attrs map[string]string `json:"attrs,omitempty"`
+}
+
+// An Edge of the graph, from a node to another.
+type Edge struct {
+	From  string            `json:"from"`
+	To    string            `json:"to"`
+	Attrs map[string]string `json:"attrs,omitempty"`
+}
+
+// A View is the state of a viewer displaying the graph.
+type View struct {
+	// Nodes fixed at a position, by ID.
+	Pins map[string]Position `json:"pins,omitempty"`
+}
+
+// A Position in a laid out graph, in inches.
+type Position struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// Schema is the JSON Schema of Artifact.
+const Schema = `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "title": "valuegraph artifact",
+  "type": "object",
+  "required": ["version", "nodes", "edges"],
+  "properties": {
+    "version": {"const": 1},
+    "metadata": {"type": "object", "additionalProperties": {"type": "string"}},
+    "nodes": {
+      "type": "array",
+      "items": {
+        "type": "object",
+        "required": ["id", "label"],
+        "properties": {
+          "id": {"type": "string", "minLength": 1},
+          "label": {"type": "string"},
+          "path": {"type": "string"},
+          "attrs": {"type": "object", "additionalProperties": {"type": "string"}}
+        }
+      }
+    },
+    "edges": {
+      "type": "array",
+      "items": {
+        "type": "object",
+        "required": ["from", "to"],
+        "properties": {
+          "from": {"type": "string"},
+          "to": {"type": "string"},
+          "attrs": {"type": "object", "additionalProperties": {"type": "string"}}
+        }
+      }
+    },
+    "view": {
+      "type": "object",
+      "properties": {
+        "pins": {
+          "type": "object",
+          "additionalProperties": {
+            "type": "object",
+            "required": ["x", "y"],
+            "properties": {"x": {"type": "number"}, "y": {"type": "number"}}
+          }
+        }
+      }
+    },
+    "dot": {"type": "string"}
+  }
+}
+`
+
+// Validate checks that a is well formed: it has a known version, node IDs are unique and
+// non-empty, and edges and pins refer to existing nodes.
+func Validate(a *Artifact) error {
+	if a.Version != Version {
+		return fmt.Errorf("artifactspec: unsupported version %d", a.Version)
+	}
+	ids := make(map[string]bool, len(a.Nodes))
+	for i, n := range a.Nodes {
+		if n.ID == "" {
+			return fmt.Errorf("artifactspec: node %d has no ID", i)
+		}
+		if ids[n.ID] {
+			return fmt.Errorf("artifactspec: duplicate node ID %q", n.ID)
+		}
+		ids[n.ID] = true
+	}
+	for i, e := range a.Edges {
+		if !ids[e.From] || !ids[e.To] {
+			return fmt.Errorf("artifactspec: edge %d from %q to %q refers to an unknown node", i, e.From, e.To)
+		}
+	}
+	if a.View != nil {
+		for id := range a.View.Pins {
+			if !ids[id] {
+				return fmt.Errorf("artifactspec: pin refers to unknown node %q", id)
+			}
+		}
+	}
+	return nil
+}
+
+// Decode reads and validates an artifact.
+func Decode(r io.Reader) (*Artifact, error) {
+	var a Artifact
+	if err := json.NewDecoder(r).Decode(&a); err != nil {
+		return nil, err
+	}
+	if err := Validate(&a); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// Encode validates and writes an artifact.
+func Encode(w io.Writer, a *Artifact) error {
+	if err := Validate(a); err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	return enc.Encode(a)
+}
@@ -0,0 +1,95 @@
+package artifactspec
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func validArtifact() *Artifact {
+	return &Artifact{
+		Version:  Version,
+		Metadata: map[string]string{"type": "*main.T"},
+		Nodes: []Node{
+			{ID: "N0", Label: "*main.T", Path: "v"},
+			{ID: "N1", Label: "main.T\nstruct", Path: "v", Attrs: map[string]string{"shape": "box"}},
+		},
+		Edges: []Edge{{From: "N0", To: "N1", Attrs: map[string]string{"style": "dashed"}}},
+		View:  &View{Pins: map[string]Position{"N1": {X: 1.5, Y: -2}}},
+		Dot:   "digraph G { N0 -> N1; }",
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	want := validArtifact()
+	var b bytes.Buffer
+	if err := Encode(&b, want); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Decode(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		change func(a *Artifact)
+		err    string
+	}{
+		{"valid", func(a *Artifact) {}, ""},
+		{"no version", func(a *Artifact) { a.Version = 0 }, "unsupported version 0"},
+		{"future version", func(a *Artifact) { a.Version = Version + 1 }, "unsupported version"},
+		{"node without ID", func(a *Artifact) { a.Nodes[1].ID = "" }, "node 1 has no ID"},
+		{"duplicate node ID", func(a *Artifact) { a.Nodes[1].ID = "N0" }, `duplicate node ID "N0"`},
+		{"edge from unknown node", func(a *Artifact) { a.Edges[0].From = "N9" }, "edge 0"},
+		{"edge to unknown node", func(a *Artifact) { a.Edges[0].To = "N9" }, "unknown node"},
+		{"pin of unknown node", func(a *Artifact) { a.View.Pins["N9"] = Position{} }, `unknown node "N9"`},
+	} {
+		a := validArtifact()
+		tc.change(a)
+		err := Validate(a)
+		switch {
+		case tc.err == "" && err != nil:
+			t.Errorf("%s: %v", tc.name, err)
+		case tc.err != "" && (err == nil || !strings.Contains(err.Error(), tc.err)):
+			t.Errorf("%s: got error %v, want one with %q", tc.name, err, tc.err)
+		}
+		if tc.err != "" {
+			if err := Encode(&bytes.Buffer{}, a); err == nil {
+				t.Errorf("%s: encoded", tc.name)
+			}
+		}
+	}
+}
+
+func TestDecodeRejectsInvalid(t *testing.T) {
+	for _, in := range []string{
+		`{"nodes": [], "edges": []}`,
+		`{"version": 1, "nodes": [{"id": "a"}, {"id": "a"}], "edges": []}`,
+		`{"version": 1, "nodes": [], "edges": [{"from": "a", "to": "b"}]}`,
+		`not json`,
+	} {
+		if _, err := Decode(strings.NewReader(in)); err == nil {
+			t.Errorf("decoded %s", in)
+		}
+	}
+}
+
+func TestSchemaIsJSON(t *testing.T) {
+	var schema struct {
+		Required []string `json:"required"`
+	}
+	if err := json.Unmarshal([]byte(Schema), &schema); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"version", "nodes", "edges"}; !reflect.DeepEqual(schema.Required, want) {
+		t.Errorf("required fields %q, want %q", schema.Required, want)
+	}
+}
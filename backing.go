@@ -0,0 +1,74 @@
+package valuegraph
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// A backingArray is the part of an array seen through the slices graphed so far that overlap
+// in it.
+type backingArray struct {
+	elem       reflect.Type
+	start, end uintptr
+	slices     []sliceView
+}
+
+// A sliceView is a slice's node and its range in its backing array.
+type sliceView struct {
+	node     string
+	ptr      uintptr
+	len, cap int
+}
+
+// addSliceView records the slice v, graphed as node, so that slices sharing a backing array
+// are linked to a node for it once the walk is done.
+func (g *Graph) addSliceView(node string, v reflect.Value) {
+	elem := v.Type().Elem()
+	size := elem.Size()
+	if size == 0 || v.Cap() == 0 {
+		return
+	}
+	s := sliceView{node: node, ptr: v.Pointer(), len: v.Len(), cap: v.Cap()}
+	a := &backingArray{elem: elem, start: s.ptr, end: s.ptr + uintptr(s.cap)*size, slices: []sliceView{s}}
+
+	// Merge all the arrays the slice overlaps with, since it shows they're the same.
+	arrays := g.arrays[:0]
+	for _, b := range g.arrays {
+		if b.elem != elem || b.end <= a.start || a.end <= b.start {
+			arrays = append(arrays, b)
+			continue
+		}
+		if b.start < a.start {
+			a.start = b.start
+		}
+		if b.end > a.end {
+			a.end = b.end
+		}
+		a.slices = append(b.slices, a.slices...)
+	}
+	g.arrays = append(arrays, a)
+}
+
+// addBackingArrays adds a node for each backing array shared by slices, linked from them by
+// edges labeled with their range in it, as in a full slice expression.
+func (g *Graph) addBackingArrays() {
+	for _, a := range g.arrays {
+		if len(a.slices) < 2 {
+			continue
+		}
+		size := a.elem.Size()
+		node := g.nextNode()
+		g.addNode("G", node, map[string]string{
+			"shape": "box3d",
+			"label": quote(fmt.Sprintf("backing array\n[%v]%v\nshared by %v slices", (a.end-a.start)/size, a.elem, len(a.slices))),
+		})
+		for _, s := range a.slices {
+			off := int((s.ptr - a.start) / size)
+			g.addEdge(s.node, node, map[string]string{
+				"style": "dotted",
+				"label": quote(fmt.Sprintf("[%v:%v:%v]", off, off+s.len, off+s.cap)),
+			})
+		}
+	}
+	g.arrays = nil
+}
@@ -0,0 +1,158 @@
+package valuegraph
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+)
+
+// A ByteLayout describes fields encoded in a []byte, so that it can be graphed as those fields
+// instead of as bytes. See Config.ByteLayouts.
+type ByteLayout []ByteField
+
+// A ByteField is a field encoded in a []byte.
+type ByteField struct {
+	Name   string
+	Offset int
+	Kind   ByteKind
+	// Length in bytes for ByteBytes and ByteString. Ignored for other kinds, whose length is fixed.
+	Len int
+	// Byte order of numbers. Big endian, as in network protocols, by default.
+	LittleEndian bool
+}
+
+// A ByteKind is how a ByteField is encoded. The zero ByteKind is invalid, so that a ByteField
+// without one fails to decode instead of being read as some kind.
+type ByteKind int
+
+const (
+	ByteUint8 ByteKind = iota + 1
+	ByteInt8
+	ByteUint16
+	ByteInt16
+	ByteUint32
+	ByteInt32
+	ByteUint64
+	ByteInt64
+	ByteFloat32
+	ByteFloat64
+	// Raw bytes, shown in hex.
+	ByteBytes
+	// Text.
+	ByteString
+)
+
+var byteKindNames = [...]string{"", "uint8", "int8", "uint16", "int16", "uint32", "int32", "uint64", "int64", "float32", "float64", "bytes", "string"}
+
+func (k ByteKind) String() string {
+	if k < ByteUint8 || int(k) >= len(byteKindNames) {
+		return "ByteKind(" + strconv.Itoa(int(k)) + ")"
+	}
+	return byteKindNames[k]
+}
+
+func (f ByteField) size() int {
+	switch f.Kind {
+	case ByteUint8, ByteInt8:
+		return 1
+	case ByteUint16, ByteInt16:
+		return 2
+	case ByteUint32, ByteInt32, ByteFloat32:
+		return 4
+	case ByteUint64, ByteInt64, ByteFloat64:
+		return 8
+	}
+	return f.Len
+}
+
+// decode returns the field's value as text, decoded from b.
+func (f ByteField) decode(b []byte) (string, error) {
+	n := f.size()
+	if f.Offset < 0 || n < 0 || f.Offset+n > len(b) {
+		return "", fmt.Errorf("bytes [%v:%v] out of range", f.Offset, f.Offset+n)
+	}
+	b = b[f.Offset : f.Offset+n]
+
+	var order binary.ByteOrder = binary.BigEndian
+	if f.LittleEndian {
+		order = binary.LittleEndian
+	}
+	switch f.Kind {
+	case ByteUint8:
+		return fmt.Sprint(b[0]), nil
+	case ByteInt8:
+		return fmt.Sprint(int8(b[0])), nil
+	case ByteUint16:
+		return fmt.Sprint(order.Uint16(b)), nil
+	case ByteInt16:
+		return fmt.Sprint(int16(order.Uint16(b))), nil
+	case ByteUint32:
+		return fmt.Sprint(order.Uint32(b)), nil
+	case ByteInt32:
+		return fmt.Sprint(int32(order.Uint32(b))), nil
+	case ByteUint64:
+		return fmt.Sprint(order.Uint64(b)), nil
+	case ByteInt64:
+		return fmt.Sprint(int64(order.Uint64(b))), nil
+	case ByteFloat32:
+		return fmt.Sprint(math.Float32frombits(order.Uint32(b))), nil
+	case ByteFloat64:
+		return fmt.Sprint(math.Float64frombits(order.Uint64(b))), nil
+	case ByteBytes:
+		return hex.EncodeToString(b), nil
+	case ByteString:
+		return strconv.Quote(string(b)), nil
+	}
+	return "", fmt.Errorf("unknown kind %v", f.Kind)
+}
+
+// A byteLayoutRule is a compiled entry of Config.ByteLayouts.
+type byteLayoutRule struct {
+	pattern pathPattern
+	layout  ByteLayout
+}
+
+func compileByteLayouts(m map[string]ByteLayout) []byteLayoutRule {
+	var rules []byteLayoutRule
+	for p, l := range m {
+		for _, pp := range compilePatterns([]string{p}) {
+			rules = append(rules, byteLayoutRule{pp, l})
+		}
+	}
+	return rules
+}
+
+// byteLayout returns the layout registered for the []byte at path, if any.
+func (g *Graph) byteLayout(path string, v reflect.Value) (ByteLayout, bool) {
+	if len(g.byteLayouts) == 0 || v.Type().Elem().Kind() != reflect.Uint8 {
+		return nil, false
+	}
+	rel := g.relPath(path)
+	for _, r := range g.byteLayouts {
+		if r.pattern.match(lastName(rel), rel) {
+			return r.layout, true
+		}
+	}
+	return nil, false
+}
+
+// addByteFields adds a child to node for each field in the layout, decoded from b.
+func (g *Graph) addByteFields(node string, b []byte, layout ByteLayout, path string) {
+	for _, f := range layout {
+		label := fmt.Sprintf("%v\n%v [%v:%v]: ", f.Name, f.Kind, f.Offset, f.Offset+f.size())
+		s, err := f.decode(b)
+		if err != nil {
+			label += "(" + err.Error() + ")"
+		} else {
+			label += s
+		}
+		g.addChild(node, map[string]string{
+			"label":   quote(label),
+			"shape":   "box",
+			"tooltip": quote(path + "." + f.Name),
+		})
+	}
+}
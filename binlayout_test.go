@@ -0,0 +1,36 @@
+package valuegraph
+
+import "testing"
+
+func TestByteFieldDecode(t *testing.T) {
+	b := []byte{0x01, 0xff, 0xfe, 0x3f, 0x80, 0x00, 0x00, 'h', 'i', '"'}
+	for _, tc := range []struct {
+		f       ByteField
+		want    string
+		wantErr bool
+	}{
+		{f: ByteField{Kind: ByteUint8}, want: "1"},
+		{f: ByteField{Offset: 1, Kind: ByteUint8}, want: "255"},
+		{f: ByteField{Offset: 1, Kind: ByteInt8}, want: "-1"},
+		{f: ByteField{Offset: 1, Kind: ByteUint16}, want: "65534"},
+		{f: ByteField{Offset: 1, Kind: ByteUint16, LittleEndian: true}, want: "65279"},
+		{f: ByteField{Offset: 1, Kind: ByteInt16}, want: "-2"},
+		{f: ByteField{Kind: ByteUint32}, want: "33553983"},
+		{f: ByteField{Offset: 1, Kind: ByteInt32}, want: "-114816"},
+		{f: ByteField{Offset: 3, Kind: ByteFloat32}, want: "1"},
+		{f: ByteField{Kind: ByteUint64}, want: "144113261783023720"},
+		{f: ByteField{Kind: ByteBytes, Len: 3}, want: "01fffe"},
+		{f: ByteField{Offset: 7, Kind: ByteString, Len: 3}, want: `"hi\""`},
+		{f: ByteField{Offset: 8, Kind: ByteUint16}, want: "26914"},
+		{f: ByteField{Offset: 9, Kind: ByteUint16}, wantErr: true},
+		{f: ByteField{Offset: -1, Kind: ByteUint8}, wantErr: true},
+		{f: ByteField{Kind: ByteBytes, Len: 11}, wantErr: true},
+		{f: ByteField{Kind: ByteKind(99)}, wantErr: true},
+		{f: ByteField{Offset: 1}, wantErr: true},
+	} {
+		got, err := tc.f.decode(b)
+		if (err != nil) != tc.wantErr || got != tc.want {
+			t.Errorf("%+v: got %q, %v; want %q, error %v", tc.f, got, err, tc.want, tc.wantErr)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package valuegraph
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+)
+
+// Bits beyond this many aren't drawn in bitmaps.
+const maxBitmapBits = 4096
+
+var bigIntType = reflect.TypeOf(big.Int{})
+
+// A bitset is implemented by common bitset types, like github.com/bits-and-blooms/bitset.
+type bitset interface {
+	Len() uint
+	Test(i uint) bool
+}
+
+// bitmap draws []bool, [N]bool, big.Int and bitset values as strips of blocks.
+func (g *Graph) bitmap(v reflect.Value) (string, bool) {
+	var n int
+	var bit func(i int) bool
+	var what string
+
+	switch {
+	case (v.Kind() == reflect.Slice || v.Kind() == reflect.Array) && v.Type().Elem().Kind() == reflect.Bool:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return "", false
+		}
+		n, bit, what = v.Len(), func(i int) bool { return v.Index(i).Bool() }, "bits"
+	case v.Type() == bigIntType:
+		x, ok := g.exposed(v)
+		if !ok {
+			return "", false
+		}
+		b := x.Interface().(big.Int)
+		var abs big.Int
+		abs.Abs(&b)
+		n, bit, what = abs.BitLen(), func(i int) bool { return abs.Bit(n-1-i) == 1 }, "bits, most significant first"
+		if b.Sign() < 0 {
+			what += ", negative"
+		}
+	default:
+		bs, ok := g.asBitset(v)
+		if !ok {
+			return "", false
+		}
+		n, bit, what = int(bs.Len()), func(i int) bool { return bs.Test(uint(i)) }, "bits"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "\n%v %v", n, what)
+	shown := n
+	if shown > maxBitmapBits {
+		shown = maxBitmapBits
+	}
+	for i := 0; i < shown; i++ {
+		if i%g.cfg.BitmapWidth == 0 {
+			b.WriteString("\n")
+		}
+		if bit(i) {
+			b.WriteString("█")
+		} else {
+			b.WriteString("░")
+		}
+	}
+	if shown < n {
+		fmt.Fprintf(&b, "\n... %v more", n-shown)
+	}
+	return b.String(), true
+}
+
+func (g *Graph) asBitset(v reflect.Value) (bitset, bool) {
+	if v.Kind() != reflect.Ptr && v.CanAddr() {
+		v = v.Addr()
+	}
+	x, ok := g.exposed(v)
+	if !ok {
+		return nil, false
+	}
+	if v.Kind() == reflect.Ptr && v.IsNil() {
+		return nil, false
+	}
+	bs, ok := x.Interface().(bitset)
+	return bs, ok
+}
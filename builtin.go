@@ -0,0 +1,106 @@
+package valuegraph
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"time"
+)
+
+// Digits of big numbers beyond this many aren't shown.
+const maxBigDigits = 40
+
+var (
+	bigFloatType = reflect.TypeOf(big.Float{})
+	bigRatType   = reflect.TypeOf(big.Rat{})
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
+// A builtinRenderer renders a value as text, for types better shown as a single node than by
+// walking into their internals. v can be called Interface on.
+type builtinRenderer func(c *Config, v reflect.Value) string
+
+// builtinRenderers are the built-in renderers, by type.
+var builtinRenderers = map[reflect.Type]builtinRenderer{
+	bigIntType:   renderBigInt,
+	bigFloatType: renderBigFloat,
+	bigRatType:   renderBigRat,
+	timeType:     renderTime,
+	durationType: renderDuration,
+
+	httpRequestType:  renderHTTPRequest,
+	httpResponseType: renderHTTPResponse,
+
+	atomicInt32Type:   renderAtomicInt32,
+	atomicInt64Type:   renderAtomicInt64,
+	atomicUint32Type:  renderAtomicUint32,
+	atomicUint64Type:  renderAtomicUint64,
+	atomicUintptrType: renderAtomicUintptr,
+	atomicBoolType:    renderAtomicBool,
+
+	mutexType:     renderMutex,
+	rwMutexType:   renderRWMutex,
+	waitGroupType: renderWaitGroup,
+	onceType:      renderOnce,
+}
+
+// builtin renders v with its built-in renderer, if it has one.
+func (g *Graph) builtin(v reflect.Value) (string, bool) {
+	r, ok := builtinRenderers[v.Type()]
+	if !ok {
+		return "", false
+	}
+	x, ok := g.exposed(v)
+	if !ok {
+		return "", false
+	}
+	return r(g.cfg, x), true
+}
+
+func renderBigInt(_ *Config, v reflect.Value) string {
+	b := v.Interface().(big.Int)
+	return "\n" + truncDigits(b.String())
+}
+
+func renderBigFloat(_ *Config, v reflect.Value) string {
+	f := v.Interface().(big.Float)
+	return fmt.Sprintf("\n%v\nprec: %v bits, %v", f.Text('g', maxBigDigits), f.Prec(), f.Acc())
+}
+
+func renderBigRat(_ *Config, v reflect.Value) string {
+	r := v.Interface().(big.Rat)
+	if r.IsInt() {
+		return "\n" + truncDigits(r.Num().String())
+	}
+	return "\n" + truncDigits(r.Num().String()) + "\n/ " + truncDigits(r.Denom().String()) +
+		"\n≈ " + r.FloatString(maxBigDigits/2)
+}
+
+func renderTime(c *Config, v reflect.Value) string {
+	t := v.Interface().(time.Time)
+	if t.IsZero() {
+		return "\n(zero)"
+	}
+	layout := c.TimeLayout
+	if layout == "" {
+		layout = time.RFC3339Nano
+	}
+	return "\n" + t.Format(layout) + "\n" + t.Location().String()
+}
+
+func renderDuration(_ *Config, v reflect.Value) string {
+	return ": " + v.Interface().(time.Duration).String()
+}
+
+// truncDigits cuts the decimal number s to maxBigDigits, noting how many digits it has.
+func truncDigits(s string) string {
+	n := len(s)
+	if s[0] == '-' {
+		n--
+	}
+	if n <= maxBigDigits {
+		return s
+	}
+	return fmt.Sprintf("%v…\n(%v digits)", s[:len(s)-n+maxBigDigits], n)
+}
@@ -0,0 +1,92 @@
+package valuegraph
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime"
+	"strconv"
+)
+
+// MakeCall calls the function fn with args and graphs the arguments, as they were before the
+// call, and the results, each in its own cluster, for a quick look at what a function does
+// with an example input. If fn panics, the panic value is graphed instead of the results.
+// Arguments are converted to the types of fn's parameters, like 1 to int64; nil is the zero
+// value.
+// It uses DefaultConfig.
+func MakeCall(fn interface{}, args ...interface{}) (*Graph, error) {
+	return DefaultConfig.MakeCall(fn, args...)
+}
+
+// MakeCall calls the function fn with args and graphs the arguments, as they were before the
+// call, and the results, each in its own cluster, for a quick look at what a function does
+// with an example input. If fn panics, the panic value is graphed instead of the results.
+// Arguments are converted to the types of fn's parameters, like 1 to int64; nil is the zero
+// value.
+func (c *Config) MakeCall(fn interface{}, args ...interface{}) (*Graph, error) {
+	fv := reflect.ValueOf(fn)
+	if fv.Kind() != reflect.Func || fv.IsNil() {
+		return nil, errors.New("valuegraph: MakeCall of non-function")
+	}
+	ft := fv.Type()
+	if len(args) < ft.NumIn()-1 || !ft.IsVariadic() && len(args) != ft.NumIn() {
+		return nil, fmt.Errorf("valuegraph: MakeCall with %v arguments to %v", len(args), ft)
+	}
+	in := make([]reflect.Value, len(args))
+	for i, a := range args {
+		pt := paramType(ft, i)
+		if a == nil {
+			in[i] = reflect.Zero(pt)
+			continue
+		}
+		av := reflect.ValueOf(a)
+		switch {
+		case av.Type().AssignableTo(pt):
+			in[i] = av
+		// Converting numbers to strings makes runes of them, which is never what's meant.
+		case av.Type().ConvertibleTo(pt) && (pt.Kind() != reflect.String || av.Kind() == reflect.String):
+			in[i] = av.Convert(pt)
+		default:
+			return nil, fmt.Errorf("valuegraph: MakeCall argument %v: %v isn't convertible to %v", i, av.Type(), pt)
+		}
+	}
+
+	name := ft.String()
+	if f := runtime.FuncForPC(fv.Pointer()); f != nil {
+		name = f.Name()
+	}
+	g := c.newTitledGraph(name)
+	for i, a := range in {
+		label := "arg " + strconv.Itoa(i)
+		g.walkCluster("cluster_in_"+strconv.Itoa(i), label, a, label)
+	}
+
+	out, panicked, p := guardedCall(fv, in)
+	if panicked {
+		g.walkCluster("cluster_panic", "panic", reflect.ValueOf(&p).Elem(), "panic")
+		return g, nil
+	}
+	for i, r := range out {
+		label := "result " + strconv.Itoa(i)
+		g.walkCluster("cluster_out_"+strconv.Itoa(i), label, r, label)
+	}
+	return g, nil
+}
+
+// paramType returns the type of the argument i in a call to a function of type ft.
+func paramType(ft reflect.Type, i int) reflect.Type {
+	if ft.IsVariadic() && i >= ft.NumIn()-1 {
+		return ft.In(ft.NumIn() - 1).Elem()
+	}
+	return ft.In(i)
+}
+
+// guardedCall calls fv with in, recovering from panics.
+func guardedCall(fv reflect.Value, in []reflect.Value) (out []reflect.Value, panicked bool, p interface{}) {
+	defer func() {
+		if r := recover(); r != nil {
+			panicked, p = true, r
+		}
+	}()
+	return fv.Call(in), false, nil
+}
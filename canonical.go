@@ -0,0 +1,193 @@
+package valuegraph
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/awalterschulze/gographviz"
+)
+
+// Canonical rewrites a graph in dot format, like the output of Graph.Dot, into its canonical
+// form, so that golden files and diffs of graphs only change when the graphs do.
+//
+// The canonical form is part of this package's compatibility promise: for a given input graph
+// it won't change across versions of this package. It is:
+//
+//   - The graph header, "digraph", "graph", "strict digraph" or "strict graph", followed by the
+//     graph name and " {", in its own line.
+//   - Graph attributes, one per line, as `name="value";`, sorted by name.
+//   - Subgraphs, sorted by name, as "subgraph name {" followed by their attributes as above,
+//     their nested subgraphs and the names of the nodes in them, one per line, sorted by name,
+//     and a closing "}" line.
+//   - Nodes, one per line, as `name [ attr="value" ... ];`, sorted by name, with attributes
+//     sorted by name.
+//   - Edges, one per line, as `src -> dst [ attr="value" ... ];` (or "--" for undirected
+//     graphs), sorted by source, destination and attributes.
+//   - A closing "}" line.
+//
+// Each line inside the graph is indented by one tab per level of nesting. Names are sorted
+// with runs of digits compared by value, so that N2 comes before N10. Attribute values are
+// always double-quoted, except HTML strings; ports are dropped, as this package doesn't
+// produce them. Statements without attributes omit the brackets.
+//
+// What graphs this package produces for a given value, including node names and labels, is not
+// part of the promise.
+func Canonical(dot string) (string, error) {
+	g, err := gographviz.Read([]byte(dot))
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	if g.Strict {
+		b.WriteString("strict ")
+	}
+	op := " -- "
+	if g.Directed {
+		b.WriteString("digraph ")
+		op = " -> "
+	} else {
+		b.WriteString("graph ")
+	}
+	b.WriteString(g.Name + " {\n")
+
+	writeCanonicalAttrs(&b, "\t", g.Attrs)
+
+	isSub := func(name string) bool { return g.IsSubGraph(name) }
+	var subs []string
+	for name := range g.SubGraphs.SubGraphs {
+		top := true
+		for parent := range g.Relations.ChildToParents[name] {
+			if isSub(parent) {
+				top = false
+			}
+		}
+		if top {
+			subs = append(subs, name)
+		}
+	}
+	sortNatural(subs)
+	for _, name := range subs {
+		writeCanonicalSubGraph(&b, "\t", g, name)
+	}
+
+	nodes := make([]string, 0, len(g.Nodes.Nodes))
+	for _, n := range g.Nodes.Nodes {
+		nodes = append(nodes, n.Name)
+	}
+	sortNatural(nodes)
+	for _, name := range nodes {
+		b.WriteString("\t" + name + canonicalAttrList(g.Nodes.Lookup[name].Attrs) + ";\n")
+	}
+
+	edges := make([]string, 0, len(g.Edges.Edges))
+	for _, e := range g.Edges.Edges {
+		edges = append(edges, e.Src+op+e.Dst+canonicalAttrList(e.Attrs))
+	}
+	sortNatural(edges)
+	for _, e := range edges {
+		b.WriteString("\t" + e + ";\n")
+	}
+
+	b.WriteString("}\n")
+	return b.String(), nil
+}
+
+func writeCanonicalSubGraph(b *strings.Builder, indent string, g *gographviz.Graph, name string) {
+	b.WriteString(indent + "subgraph " + name + " {\n")
+	writeCanonicalAttrs(b, indent+"\t", g.SubGraphs.SubGraphs[name].Attrs)
+	var subs, nodes []string
+	for child := range g.Relations.ParentToChildren[name] {
+		if g.IsSubGraph(child) {
+			subs = append(subs, child)
+		} else if g.IsNode(child) {
+			nodes = append(nodes, child)
+		}
+	}
+	sortNatural(subs)
+	for _, sub := range subs {
+		writeCanonicalSubGraph(b, indent+"\t", g, sub)
+	}
+	sortNatural(nodes)
+	for _, n := range nodes {
+		b.WriteString(indent + "\t" + n + ";\n")
+	}
+	b.WriteString(indent + "}\n")
+}
+
+func writeCanonicalAttrs(b *strings.Builder, indent string, attrs gographviz.Attrs) {
+	for _, k := range sortedAttrNames(attrs) {
+		b.WriteString(indent + k + "=" + canonicalValue(attrs[gographviz.Attr(k)]) + ";\n")
+	}
+}
+
+func canonicalAttrList(attrs gographviz.Attrs) string {
+	if len(attrs) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString(" [")
+	for _, k := range sortedAttrNames(attrs) {
+		b.WriteString(" " + k + "=" + canonicalValue(attrs[gographviz.Attr(k)]))
+	}
+	b.WriteString(" ]")
+	return b.String()
+}
+
+func sortedAttrNames(attrs gographviz.Attrs) []string {
+	names := make([]string, 0, len(attrs))
+	for k := range attrs {
+		names = append(names, string(k))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// canonicalValue double-quotes an attribute value as found in dot source, unless it's already
+// quoted or an HTML string.
+func canonicalValue(v string) string {
+	if strings.HasPrefix(v, `"`) || strings.HasPrefix(v, "<") {
+		return v
+	}
+	return `"` + v + `"`
+}
+
+// sortNatural sorts s comparing runs of digits by value.
+func sortNatural(s []string) {
+	sort.SliceStable(s, func(i, j int) bool {
+		return lessNatural(s[i], s[j])
+	})
+}
+
+func lessNatural(a, b string) bool {
+	for a != "" && b != "" {
+		da, db := digitPrefix(a), digitPrefix(b)
+		if da > 0 && db > 0 {
+			na, _ := strconv.ParseUint(strings.TrimLeft(a[:da], "0"), 10, 64)
+			nb, _ := strconv.ParseUint(strings.TrimLeft(b[:db], "0"), 10, 64)
+			if na != nb {
+				return na < nb
+			}
+			if da != db {
+				return da < db
+			}
+			a, b = a[da:], b[db:]
+			continue
+		}
+		if a[0] != b[0] {
+			return a[0] < b[0]
+		}
+		a, b = a[1:], b[1:]
+	}
+	return len(a) < len(b)
+}
+
+// digitPrefix returns the length of the run of ASCII digits at the start of s.
+func digitPrefix(s string) int {
+	i := 0
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+	return i
+}
@@ -0,0 +1,71 @@
+package valuegraph
+
+import "testing"
+
+func TestCanonical(t *testing.T) {
+	for _, tc := range []struct {
+		name, dot, want string
+	}{{
+		name: "empty",
+		dot:  `digraph G {}`,
+		want: "digraph G {\n}\n",
+	}, {
+		name: "natural order and quoting",
+		dot: `digraph G { rankdir=LR; N10 [ shape=box, label="ten" ]; N2; N1 [ label=<<b>one</b>> ];
+			N10 -> N2 [ color=red ]; N1 -> N10; }`,
+		want: "digraph G {\n" +
+			"\trankdir=\"LR\";\n" +
+			"\tN1 [ label=<<b>one</b>> ];\n" +
+			"\tN2;\n" +
+			"\tN10 [ label=\"ten\" shape=\"box\" ];\n" +
+			"\tN1 -> N10;\n" +
+			"\tN10 -> N2 [ color=\"red\" ];\n" +
+			"}\n",
+	}, {
+		name: "undirected with subgraphs",
+		dot:  `strict graph G { subgraph cluster1 { label="c"; subgraph cluster0 { N1; } N0; } N0 -- N1; }`,
+		want: "strict graph G {\n" +
+			"\tsubgraph cluster1 {\n" +
+			"\t\tlabel=\"c\";\n" +
+			"\t\tsubgraph cluster0 {\n" +
+			"\t\t\tN1;\n" +
+			"\t\t}\n" +
+			"\t\tN0;\n" +
+			"\t}\n" +
+			"\tN0;\n" +
+			"\tN1;\n" +
+			"\tN0 -- N1;\n" +
+			"}\n",
+	}} {
+		got, err := Canonical(tc.dot)
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("%s: got\n%s\nwant\n%s", tc.name, got, tc.want)
+		}
+	}
+	if _, err := Canonical("digraph {"); err == nil {
+		t.Errorf("no error for invalid dot")
+	}
+}
+
+func TestLessNatural(t *testing.T) {
+	for _, tc := range []struct {
+		a, b string
+		less bool
+	}{
+		{"N2", "N10", true},
+		{"N10", "N2", false},
+		{"N02", "N2", false},
+		{"N2", "N02", true},
+		{"N1a", "N1b", true},
+		{"N", "N1", true},
+		{"a", "a", false},
+	} {
+		if got := lessNatural(tc.a, tc.b); got != tc.less {
+			t.Errorf("lessNatural(%q, %q) = %v, want %v", tc.a, tc.b, got, tc.less)
+		}
+	}
+}
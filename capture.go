@@ -0,0 +1,129 @@
+package valuegraph
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/tcard/valuegraph/gographvizutil"
+)
+
+// A DropPolicy decides what a full CaptureQueue drops.
+type DropPolicy int
+
+const (
+	// DropNewest drops the capture being queued.
+	DropNewest DropPolicy = iota
+	// DropOldest drops the capture that has waited the longest, to make room.
+	DropOldest
+)
+
+// A Capture is a value graphed by a CaptureQueue, once rendered.
+type Capture struct {
+	// As passed to CaptureQueue.Capture.
+	Name string
+	// When the value was graphed.
+	Time time.Time
+	// The graph in the format of the queue, or the error rendering it.
+	Output string
+	Err    error
+}
+
+// A CaptureQueue graphs values on the calling goroutine, which is enough to keep a snapshot of
+// them, and renders the graphs on a pool of workers, so that hooks in production code don't
+// wait for the dot command. When more captures than it holds are waiting to be rendered, it
+// drops some per its DropPolicy instead of blocking.
+type CaptureQueue struct {
+	cfg     *Config
+	format  gographvizutil.Format
+	policy  DropPolicy
+	handle  func(Capture)
+	mu      sync.Mutex
+	closed  bool
+	queue   chan *queuedCapture
+	wg      sync.WaitGroup
+	dropped uint64
+}
+
+type queuedCapture struct {
+	name string
+	time time.Time
+	g    *Graph
+}
+
+// NewCaptureQueue returns a CaptureQueue holding up to size graphs waiting to be rendered in
+// the given format by the given number of workers, which call handle with each rendered
+// Capture. Close it to stop its workers.
+func (c *Config) NewCaptureQueue(size, workers int, policy DropPolicy, format gographvizutil.Format, handle func(Capture)) *CaptureQueue {
+	if size < 1 {
+		size = 1
+	}
+	if workers < 1 {
+		workers = 1
+	}
+	q := &CaptureQueue{
+		cfg:    c,
+		format: format,
+		policy: policy,
+		handle: handle,
+		queue:  make(chan *queuedCapture, size),
+	}
+	q.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go q.work()
+	}
+	return q
+}
+
+// Capture graphs v and queues it for rendering. It reports false if the capture was dropped,
+// because the queue is full and its policy is DropNewest, or because it's closed.
+func (q *CaptureQueue) Capture(name string, v interface{}) bool {
+	c := &queuedCapture{name: name, time: time.Now(), g: q.cfg.Make(v)}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		atomic.AddUint64(&q.dropped, 1)
+		return false
+	}
+	for {
+		select {
+		case q.queue <- c:
+			return true
+		default:
+		}
+		if q.policy == DropNewest {
+			atomic.AddUint64(&q.dropped, 1)
+			return false
+		}
+		select {
+		case <-q.queue:
+			atomic.AddUint64(&q.dropped, 1)
+		default:
+		}
+	}
+}
+
+// Dropped returns how many captures have been dropped so far.
+func (q *CaptureQueue) Dropped() uint64 {
+	return atomic.LoadUint64(&q.dropped)
+}
+
+// Close stops accepting captures and waits for the queued ones to be rendered and handled.
+func (q *CaptureQueue) Close() {
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.queue)
+	}
+	q.mu.Unlock()
+	q.wg.Wait()
+}
+
+func (q *CaptureQueue) work() {
+	defer q.wg.Done()
+	for c := range q.queue {
+		out, err := c.g.render(q.format)
+		q.handle(Capture{Name: c.name, Time: c.time, Output: out, Err: err})
+	}
+}
@@ -0,0 +1,97 @@
+package valuegraph
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// A chainCut is where a chain of pointers, like a linked list, is cut per Config.ChainLimit:
+// the elements left out, and the last one, graphed after them.
+type chainCut struct {
+	field   string
+	skipped []reflect.Value
+	last    reflect.Value
+}
+
+// findChains looks for chains longer than Config.ChainLimit starting at the struct v, through
+// each of its fields pointing to its own type, and records where to cut them.
+func (g *Graph) findChains(v reflect.Value, plan *typePlan) {
+	if g.cfg.ChainLimit <= 0 {
+		return
+	}
+	for _, i := range plan.links {
+		if k, ok := nodeKey(v.Field(i)); ok && g.chainLinks[k] {
+			// Already found from an earlier element.
+			continue
+		}
+		elems := g.chain(v, i)
+		n, keep := len(elems), g.cfg.ChainLimit
+		if n < keep+2 {
+			// Cutting wouldn't leave out more than one element.
+			continue
+		}
+		owner := v
+		if keep > 1 {
+			owner = elems[keep-2]
+		}
+		k, ok := nodeKey(owner.Field(i))
+		if !ok {
+			continue
+		}
+		g.chainCuts[k] = &chainCut{field: plan.fields[i].name, skipped: elems[keep-1 : n-1], last: elems[n-1]}
+		for _, e := range append(elems[:keep-1:keep-1], elems[n-1]) {
+			if k, ok := nodeKey(e.Field(i)); ok {
+				g.chainLinks[k] = true
+			}
+		}
+	}
+}
+
+// chainCut returns the cut of a chain at the pointer v, if any.
+func (g *Graph) chainCut(v reflect.Value) (*chainCut, bool) {
+	k, ok := nodeKey(v)
+	if !ok {
+		return nil, false
+	}
+	cut, ok := g.chainCuts[k]
+	return cut, ok
+}
+
+// chain returns the structs that follow v through its field i, until a nil pointer, a value
+// already graphed or a cycle.
+func (g *Graph) chain(v reflect.Value, i int) []reflect.Value {
+	var elems []reflect.Value
+	seen := map[uintptr]bool{}
+	for {
+		p := v.Field(i)
+		if p.IsNil() || seen[p.Pointer()] {
+			return elems
+		}
+		seen[p.Pointer()] = true
+		v = p.Elem()
+		if _, ok := g.nodeOf(v); ok {
+			return elems
+		}
+		elems = append(elems, v)
+	}
+}
+
+// addChainSummary adds a node summarizing the elements left out of a chain from the pointer
+// ptr, graphed as node, followed by the last element of the chain. Pointers to the elements
+// left out lead to the summary.
+func (g *Graph) addChainSummary(node string, ptr reflect.Value, cut *chainCut, depth int, limit int, path string) {
+	g.truncate(path, "chain limit reached", "limit", g.cfg.ChainLimit, "omitted", len(cut.skipped))
+	summary := g.nextNode()
+	g.addNode(node, summary, map[string]string{
+		"label": quote(fmt.Sprintf("... %v more %v", len(cut.skipped), ptr.Type())),
+		"shape": "box",
+		"style": "dashed",
+	})
+	g.addEdge(node, summary, map[string]string{"style": "dashed"})
+	for _, e := range cut.skipped {
+		if k, ok := nodeKey(e); ok {
+			g.Nodes[k] = summary
+		}
+	}
+	g.addPointee(summary, "", cut.last, depth, limit, map[string]string{"style": "dashed"}, path+"..."+cut.field)
+}
@@ -0,0 +1,51 @@
+package valuegraph
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// chanLabel returns the rest of the label of the channel v: its direction, element type and
+// buffer usage, and whether it's closed, per Config.ProbeChannels.
+func (g *Graph) chanLabel(v reflect.Value) string {
+	ty := v.Type()
+	dir := "bidirectional"
+	switch ty.ChanDir() {
+	case reflect.RecvDir:
+		dir = "receive-only"
+	case reflect.SendDir:
+		dir = "send-only"
+	}
+	label := fmt.Sprintf(`\n%v chan of %v`, dir, escape(ty.Elem().String()))
+	if v.IsNil() {
+		return label + `: <nil>`
+	}
+	if v.Cap() == 0 {
+		label += `\nunbuffered`
+	} else {
+		label += fmt.Sprintf(`\nbuffered: %v of %v`, v.Len(), v.Cap())
+	}
+	if closed, ok := g.closed(v); ok && closed {
+		label += `\nclosed`
+	} else if ok {
+		label += `\nopen`
+	}
+	return label
+}
+
+// closed reports whether the channel v is closed, if Config.ProbeChannels is set and it can
+// be told without receiving a value: v can be received from, is buffered and is empty.
+// Unbuffered channels are never probed, since receiving from them takes the value of any
+// blocked sender.
+func (g *Graph) closed(v reflect.Value) (closed bool, ok bool) {
+	if !g.cfg.ProbeChannels || v.Type().ChanDir()&reflect.RecvDir == 0 || v.Cap() == 0 || v.Len() > 0 {
+		return false, false
+	}
+	x, ok := g.exposed(v)
+	if !ok {
+		return false, false
+	}
+	// A closed channel yields a zero value and false; an open empty one, nothing.
+	recv, received := x.TryRecv()
+	return recv.IsValid() && !received, true
+}
@@ -0,0 +1,49 @@
+package valuegraph
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProbeChannels(t *testing.T) {
+	closedBuf := make(chan int, 1)
+	close(closedBuf)
+	closedUnbuf := make(chan int)
+	close(closedUnbuf)
+	for _, tc := range []struct {
+		name string
+		ch   interface{}
+		want string
+	}{
+		{"open buffered", make(chan int, 1), `\nopen`},
+		{"closed buffered", closedBuf, `\nclosed`},
+		{"unbuffered", make(chan int), `\nunbuffered`},
+		{"closed unbuffered", closedUnbuf, `\nunbuffered`},
+		{"send-only", make(chan<- int, 1), `of 1`},
+	} {
+		label := DefaultConfig.With(probe).newGraph().chanLabel(reflect.ValueOf(tc.ch))
+		if !strings.HasSuffix(label, tc.want) {
+			t.Errorf("%s: got label %q, want it to end with %q", tc.name, label, tc.want)
+		}
+	}
+}
+
+func TestProbeChannelsKeepsBlockedSend(t *testing.T) {
+	ch := make(chan int)
+	go func() { ch <- 42 }()
+	time.Sleep(10 * time.Millisecond)
+
+	DefaultConfig.With(probe).Make(ch)
+	select {
+	case got := <-ch:
+		if got != 42 {
+			t.Errorf("received %v, want 42", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the blocked send was consumed by graphing")
+	}
+}
+
+func probe(c *Config) { c.ProbeChannels = true }
@@ -0,0 +1,32 @@
+package valuegraph
+
+import (
+	"reflect"
+	"strconv"
+)
+
+// implCluster returns the name of the cluster for values of type ty, according to
+// Config.ClusterInterfaces, or "" if they don't go in one.
+func (g *Graph) implCluster(ty reflect.Type) (name string, iface reflect.Type) {
+	if ty.Kind() == reflect.Interface {
+		return "", nil
+	}
+	for i, it := range g.cfg.ClusterInterfaces {
+		if it.Kind() == reflect.Interface && ty.Implements(it) {
+			return "cluster_impl_" + strconv.Itoa(i), it
+		}
+	}
+	return "", nil
+}
+
+// addToCluster makes node part of the cluster with the given name, labeled with label.
+func (g *Graph) addToCluster(cluster string, label string, node string) {
+	if g.stream != nil {
+		g.stream.write("\tsubgraph " + cluster + " { label=" + quote(label) + "; " + node + "; }\n")
+		return
+	}
+	if !g.IsSubGraph(cluster) {
+		g.AddSubGraph("G", cluster, map[string]string{"label": quote(label)})
+	}
+	g.Relations.Add(cluster, node)
+}
@@ -0,0 +1,88 @@
+// Command valuegraph-gallery generates a static HTML gallery of graphs of the values in JSON
+// corpus files.
+//
+// Usage:
+//
+//	valuegraph-gallery [-out dir] [-format name] corpus.json...
+//
+// Each corpus file holds a JSON array of examples, like
+//
+//	[
+//		{"name": "Empty order", "value": {"id": 1, "items": []}},
+//		{"name": "Order with items", "value": {"id": 2, "items": [{"sku": "A1", "qty": 3}]}}
+//	]
+//
+// Values are graphed as decoded by encoding/json, with their JSON as source snippet. To graph
+// Go values, use the gallery package from a Go program instead.
+//
+// Graphs are written as SVG, or in the format of the -format flag, which can be any format
+// registered with valuegraph.RegisterExporter.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/tcard/valuegraph"
+	"github.com/tcard/valuegraph/gallery"
+)
+
+func main() {
+	out := flag.String("out", "gallery", "directory to write the gallery to")
+	format := flag.String("format", "svg", "format of the graphs: "+strings.Join(valuegraph.Exporters(), ", "))
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: valuegraph-gallery [-out dir] [-format name] corpus.json...")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var examples []gallery.Example
+	for _, path := range flag.Args() {
+		exs, err := readCorpus(path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "valuegraph-gallery:", err)
+			os.Exit(1)
+		}
+		examples = append(examples, exs...)
+	}
+
+	c := *valuegraph.DefaultConfig
+	c.SortMapKeys = true
+	if err := gallery.WriteFormat(*out, &c, *format, examples); err != nil {
+		fmt.Fprintln(os.Stderr, "valuegraph-gallery:", err)
+		os.Exit(1)
+	}
+}
+
+func readCorpus(path string) ([]gallery.Example, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var corpus []struct {
+		Name  string          `json:"name"`
+		Value json.RawMessage `json:"value"`
+	}
+	if err := json.Unmarshal(data, &corpus); err != nil {
+		return nil, fmt.Errorf("%v: %v", path, err)
+	}
+
+	examples := make([]gallery.Example, len(corpus))
+	for i, ex := range corpus {
+		var v interface{}
+		if err := json.Unmarshal(ex.Value, &v); err != nil {
+			return nil, fmt.Errorf("%v: %v: %v", path, ex.Name, err)
+		}
+		src, _ := json.MarshalIndent(v, "", "  ")
+		examples[i] = gallery.Example{Name: ex.Name, Source: string(src), Value: v}
+	}
+	return examples, nil
+}
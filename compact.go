@@ -0,0 +1,69 @@
+package valuegraph
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// compactScalar returns the text of the boolean, number or string v, at path, to fold into
+// the label of its struct's node per Config.CompactScalars, escaped for DOT.
+func (g *Graph) compactScalar(v reflect.Value, path string) (string, bool) {
+	if !g.cfg.CompactScalars {
+		return "", false
+	}
+	switch v.Kind() {
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128,
+		reflect.String:
+	default:
+		return "", false
+	}
+	if g.cfg.Highlight != nil && g.cfg.Highlight(path, v) {
+		return "", false
+	}
+	if g.redacted(path, v) {
+		return "«redacted»", true
+	}
+
+	// Values shown specially, like durations or enums with a String method, are folded as
+	// shown, if that fits in a line.
+	if s, _, ok := g.leafLabel(v, path); ok {
+		return oneLine(s)
+	}
+	if s, ok := g.stringLabel(v, path); ok {
+		return oneLine(s)
+	}
+
+	if v.Kind() == reflect.String {
+		s := v.String()
+		limit := int(g.cfg.StringLimit)
+		if g.cfg.StringTail || limit < 0 || len(s) <= limit {
+			return escape(strconv.Quote(g.truncateString(s, path))), true
+		}
+		g.truncate(path, "string limit reached", "limit", limit)
+		head := runeCut(s, limit)
+		return escape(fmt.Sprintf("%v… (%v more)", strconv.Quote(s[:head]), len(s)-head)), true
+	}
+	x, ok := g.exposed(v)
+	if !ok {
+		return "(unexported)", true
+	}
+	if n, ok := g.noisy(x); ok {
+		return "≈" + escape(fmt.Sprint(n.Interface())), true
+	}
+	return escape(fmt.Sprint(x.Interface())) + g.runeSuffix(x), true
+}
+
+// oneLine returns the rest of a label s, as returned by leafLabel, without its leading
+// separator, if it's a single line.
+func oneLine(s string) (string, bool) {
+	s = strings.TrimPrefix(strings.TrimPrefix(s, ": "), `\n`)
+	if strings.Contains(s, `\n`) || strings.Contains(s, `\l`) {
+		return "", false
+	}
+	return s, true
+}
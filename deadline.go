@@ -0,0 +1,41 @@
+package valuegraph
+
+import "time"
+
+// Levels of degradation as Config.Deadline approaches, from least to most severe.
+const (
+	noPressure = iota
+	summarizeCollections
+	skipPointers
+	stopWalking
+)
+
+func (g *Graph) pressure() int {
+	d := g.cfg.Deadline
+	if d <= 0 {
+		return noPressure
+	}
+	switch elapsed := time.Since(g.start); {
+	case elapsed >= d:
+		return stopWalking
+	case elapsed >= d*3/4:
+		return skipPointers
+	case elapsed >= d/2:
+		return summarizeCollections
+	}
+	return noPressure
+}
+
+func (g *Graph) rangeLimit() int {
+	if g.pressure() >= summarizeCollections {
+		return 0
+	}
+	return int(g.cfg.RangeLimit)
+}
+
+func (g *Graph) mapLimit() int {
+	if g.pressure() >= summarizeCollections {
+		return 0
+	}
+	return int(g.cfg.MapLimit)
+}
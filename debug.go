@@ -0,0 +1,10 @@
+package valuegraph
+
+// debug logs a decision made while walking the value at path to Config.Logger, if set, with
+// args as key-value pairs.
+func (g *Graph) debug(msg string, path string, args ...interface{}) {
+	if g.cfg.Logger == nil {
+		return
+	}
+	g.cfg.Logger.Debug(msg, append([]interface{}{"path", path}, args...)...)
+}
@@ -0,0 +1,164 @@
+package valuegraph
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"path"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/awalterschulze/gographviz"
+)
+
+// Dump graphs several values into a single graph titled label, each in its own cluster labeled
+// with the source text of the argument it was passed as, like Dump("after parse", tok, tree).
+// Source text is recovered from the caller's source file, if available; otherwise values are
+// labeled by position, as they are if spread with ... or if Dump is called more than once on
+// the same line.
+// It uses DefaultConfig.
+func Dump(label string, vs ...interface{}) *Graph {
+	return DefaultConfig.dump(label, vs, callArgs(2))
+}
+
+// Dump graphs several values into a single graph titled label, each in its own cluster labeled
+// with the source text of the argument it was passed as, like c.Dump("after parse", tok, tree).
+// Source text is recovered from the caller's source file, if available; otherwise values are
+// labeled by position, as they are if spread with ... or if Dump is called more than once on
+// the same line.
+func (c *Config) Dump(label string, vs ...interface{}) *Graph {
+	return c.dump(label, vs, callArgs(2))
+}
+
+func (c *Config) dump(label string, vs []interface{}, args []string) *Graph {
+	g := c.newTitledGraph(label)
+	for i, v := range vs {
+		expr := "arg" + strconv.Itoa(i)
+		// The first argument in the call is the label.
+		if i+1 < len(args) {
+			expr = args[i+1]
+		}
+		g.walkCluster("cluster_"+strconv.Itoa(i), expr, reflect.ValueOf(v), expr)
+	}
+	return g
+}
+
+// newTitledGraph returns an empty graph titled label, for walking values into clusters.
+func (c *Config) newTitledGraph(label string) *Graph {
+	g := c.newGraph()
+	g.Graph = gographviz.NewGraph()
+	g.SetName("G")
+	g.SetDir(true)
+	for k, v := range graphAttrs(c.Style) {
+		g.AddAttr("G", k, v)
+	}
+	g.AddAttr("G", "label", quote(label))
+	g.AddAttr("G", "labelloc", "t")
+	return g
+}
+
+// walkCluster graphs v inside a new cluster with the given name and label.
+func (g *Graph) walkCluster(name string, label string, v reflect.Value, path string) {
+	g.cluster = name
+	g.AddSubGraph("G", g.cluster, map[string]string{"label": quote(label)})
+	g.walk(v, path)
+	g.cluster = ""
+}
+
+// callArgs returns the source text of the arguments of the call to Dump at the caller skip
+// frames up, or nil if it can't be told apart from other calls on the same line, or its
+// arguments are spread, or the source can't be found.
+func callArgs(skip int) []string {
+	pcs := make([]uintptr, 2)
+	if runtime.Callers(skip, pcs) < len(pcs) {
+		return nil
+	}
+	frames := runtime.CallersFrames(pcs)
+	callee, _ := frames.Next()
+	caller, _ := frames.Next()
+	// The callee is this package's Dump function or Config.Dump method.
+	pkgPath, isMethod := strings.TrimSuffix(callee.Function, ".Dump"), false
+	if strings.HasSuffix(pkgPath, ".(*Config)") {
+		pkgPath, isMethod = strings.TrimSuffix(pkgPath, ".(*Config)"), true
+	}
+
+	src, err := ioutil.ReadFile(caller.File)
+	if err != nil {
+		return nil
+	}
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, caller.File, src, 0)
+	if err != nil {
+		return nil
+	}
+
+	// Only the line of the call is known, so several calls to Dump on it are ambiguous.
+	var calls []*ast.CallExpr
+	ast.Inspect(f, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if ok && isDumpCall(f, call, pkgPath, isMethod) &&
+			fset.Position(call.Pos()).Line <= caller.Line && fset.Position(call.End()).Line >= caller.Line {
+			calls = append(calls, call)
+		}
+		return true
+	})
+	if len(calls) != 1 || calls[0].Ellipsis.IsValid() {
+		return nil
+	}
+	var args []string
+	for _, a := range calls[0].Args {
+		args = append(args, string(src[fset.Position(a.Pos()).Offset:fset.Position(a.End()).Offset]))
+	}
+	return args
+}
+
+// isDumpCall reports whether call, in f, may call the Dump function of the package at pkgPath
+// or, if isMethod, the Dump method of its Config.
+func isDumpCall(f *ast.File, call *ast.CallExpr, pkgPath string, isMethod bool) bool {
+	switch fun := call.Fun.(type) {
+	case *ast.Ident:
+		return !isMethod && fun.Name == "Dump" && (f.Name.Name == path.Base(pkgPath) || importName(f, pkgPath) == ".")
+	case *ast.SelectorExpr:
+		if fun.Sel.Name != "Dump" {
+			return false
+		}
+		// Without type checking, a selector on anything but an imported package may be a
+		// Config.
+		x, ok := fun.X.(*ast.Ident)
+		if !ok {
+			return isMethod
+		}
+		if isMethod {
+			for _, imp := range f.Imports {
+				if importedAs(imp) == x.Name {
+					return false
+				}
+			}
+			return true
+		}
+		return importName(f, pkgPath) == x.Name
+	}
+	return false
+}
+
+// importName returns the name that f imports the package at pkgPath as, or "" if it doesn't.
+func importName(f *ast.File, pkgPath string) string {
+	for _, imp := range f.Imports {
+		if p, _ := strconv.Unquote(imp.Path.Value); p == pkgPath {
+			return importedAs(imp)
+		}
+	}
+	return ""
+}
+
+// importedAs returns the name of the package imported by imp in the importing file.
+func importedAs(imp *ast.ImportSpec) string {
+	if imp.Name != nil {
+		return imp.Name.Name
+	}
+	p, _ := strconv.Unquote(imp.Path.Value)
+	return path.Base(p)
+}
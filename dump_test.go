@@ -0,0 +1,40 @@
+package valuegraph
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+type otherDumper struct{}
+
+func (otherDumper) Dump(...interface{}) {}
+
+func TestDumpLabels(t *testing.T) {
+	a, b, xs := 1, "two", []interface{}{3, 4}
+	var o otherDumper
+
+	for _, tc := range []struct {
+		name string
+		g    *Graph
+		want []string
+	}{
+		{"function", Dump("l", a, b), []string{"a", "b"}},
+		{"method", DefaultConfig.Dump("l", b, a+1), []string{"b", "a+1"}},
+		{"spread", Dump("l", xs...), []string{"arg0", "arg1"}},
+		{"other Dump on the line", func() *Graph { o.Dump(a, b); return Dump("l", a) }(), []string{"a"}},
+		{"two on the line", func() *Graph { Dump("l", b); return Dump("l", a) }(), []string{"arg0"}},
+	} {
+		var got []string
+		for i := 0; ; i++ {
+			sub, ok := tc.g.SubGraphs.SubGraphs["cluster_"+strconv.Itoa(i)]
+			if !ok {
+				break
+			}
+			got = append(got, unquote(sub.Attrs["label"]))
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: got clusters %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
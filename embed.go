@@ -0,0 +1,42 @@
+package valuegraph
+
+import "reflect"
+
+// inlined reports whether the field f, holding fv at path, is an embedded struct to graph
+// inline, per Config.InlineEmbedded.
+func (g *Graph) inlined(f fieldPlan, fv reflect.Value, path string) bool {
+	if !g.cfg.InlineEmbedded || !f.embedded || fv.Kind() != reflect.Struct {
+		return false
+	}
+	if g.redacted(path, fv) || g.keyMaterial(fv) {
+		return false
+	}
+	// Embedded structs graphed as a single node aren't walked into.
+	if _, _, ok := g.leafLabel(fv, path); ok {
+		return false
+	}
+	// Nor are those graphed by their own methods.
+	methods := []reflect.Type{grapherType}
+	if g.cfg.UseStringers {
+		methods = append(methods, errorType, stringerType)
+	}
+	for _, it := range methods {
+		if fv.Type().Implements(it) || (fv.CanAddr() && reflect.PtrTo(fv.Type()).Implements(it)) {
+			return false
+		}
+	}
+	return true
+}
+
+// shadow returns the names of fields that shadow those promoted from structs embedded in p:
+// those in shadowed and p's own.
+func (p *typePlan) shadow(shadowed map[string]bool) map[string]bool {
+	names := make(map[string]bool, len(shadowed)+len(p.fields))
+	for name := range shadowed {
+		names[name] = true
+	}
+	for _, f := range p.fields {
+		names[f.name] = true
+	}
+	return names
+}
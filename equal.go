@@ -0,0 +1,41 @@
+package valuegraph
+
+import "reflect"
+
+// mergeLeaf merges the node of v, with the given DOT-escaped label and attributes, into an
+// equal one already graphed, per Config.MergeEqualLeaves, if v is graphed with no children and
+// there is one. Otherwise, it returns the label and edge attributes to graph it with, so that
+// later equal values can share its node: its name moves from its label to the edge to it.
+func (g *Graph) mergeLeaf(node string, parent string, varName string, v reflect.Value, label string, valueLabel string, nodeParams map[string]string, edgeParams map[string]string) (string, map[string]string, bool) {
+	if !g.cfg.MergeEqualLeaves || g.cfg.OnNode != nil || g.hasChildren[node] || parent == "G" || !v.IsValid() {
+		return label, edgeParams, false
+	}
+	key := valueLabel
+	for _, k := range sortedKeys(nodeParams) {
+		if k != "tooltip" {
+			key += "\x00" + k + "=" + nodeParams[k]
+		}
+	}
+
+	attrs := make(map[string]string, len(edgeParams)+1)
+	for k, a := range edgeParams {
+		attrs[k] = a
+	}
+	if varName != "" {
+		attrs["label"] = quote(varName)
+	}
+
+	n, ok := g.leaves[key]
+	if !ok {
+		g.leaves[key] = node
+		return valueLabel, attrs, false
+	}
+	// Pointers to v lead to the node it's merged into.
+	if k, ok := nodeKey(v); ok && g.Nodes[k] == node {
+		g.Nodes[k] = n
+	}
+	g.debug("merged into an equal node", g.paths[node], "node", n)
+	g.addShared(n, v.Type())
+	g.addEdge(parent, n, attrs)
+	return "", nil, true
+}
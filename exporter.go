@@ -0,0 +1,114 @@
+package valuegraph
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/tcard/valuegraph/gographvizutil"
+)
+
+// An Exporter writes graphs in some output format. Register exporters with RegisterExporter
+// to make their format available to Graph.Export and to tools taking a format name, like the
+// valuegraph-gallery command.
+type Exporter interface {
+	Export(g *Graph, w io.Writer) error
+}
+
+// ExporterFunc makes a function an Exporter.
+type ExporterFunc func(g *Graph, w io.Writer) error
+
+// Export calls f(g, w).
+func (f ExporterFunc) Export(g *Graph, w io.Writer) error {
+	return f(g, w)
+}
+
+var exporters = struct {
+	sync.RWMutex
+	byName map[string]Exporter
+}{byName: map[string]Exporter{}}
+
+// RegisterExporter makes e available as the exporter for the format name, typically from the
+// init function of the package implementing it. Built in are "dot", "canonical" (see
+// Canonical), "json" (see Graph.Artifact) and the formats of the dot command in
+// gographvizutil, like "svg" and "png", which need the dot command to be available.
+//
+// RegisterExporter panics if e is nil or name is already registered.
+func RegisterExporter(name string, e Exporter) {
+	exporters.Lock()
+	defer exporters.Unlock()
+	if e == nil {
+		panic("valuegraph: RegisterExporter: nil exporter for " + name)
+	}
+	if _, ok := exporters.byName[name]; ok {
+		panic("valuegraph: RegisterExporter: " + name + " already registered")
+	}
+	exporters.byName[name] = e
+}
+
+// Exporters returns the names of the registered exporters, sorted.
+func Exporters() []string {
+	exporters.RLock()
+	defer exporters.RUnlock()
+	names := make([]string, 0, len(exporters.byName))
+	for name := range exporters.byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Export writes g to w with the exporter registered for the format name.
+func (g *Graph) Export(name string, w io.Writer) error {
+	exporters.RLock()
+	e, ok := exporters.byName[name]
+	exporters.RUnlock()
+	if !ok {
+		return fmt.Errorf("valuegraph: no exporter for format %q", name)
+	}
+	if err := g.Err(); err != nil {
+		return err
+	}
+	return e.Export(g, w)
+}
+
+func init() {
+	RegisterExporter("dot", ExporterFunc(func(g *Graph, w io.Writer) error {
+		_, err := io.WriteString(w, g.Dot())
+		return err
+	}))
+	RegisterExporter("canonical", ExporterFunc(func(g *Graph, w io.Writer) error {
+		s, err := Canonical(g.Dot())
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(w, s)
+		return err
+	}))
+	RegisterExporter("json", ExporterFunc(func(g *Graph, w io.Writer) error {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(g.Artifact())
+	}))
+	for _, f := range []gographvizutil.Format{
+		gographvizutil.SVG,
+		gographvizutil.PNG,
+		gographvizutil.GIF,
+		gographvizutil.JPEG,
+		gographvizutil.PDF,
+		gographvizutil.PostScript,
+		gographvizutil.Plain,
+	} {
+		f := f
+		RegisterExporter(string(f), ExporterFunc(func(g *Graph, w io.Writer) error {
+			s, err := g.render(f)
+			if err != nil {
+				return err
+			}
+			_, err = io.WriteString(w, s)
+			return err
+		}))
+	}
+}
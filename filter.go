@@ -0,0 +1,150 @@
+package valuegraph
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// A pathPattern matches values by field name or by path, as given in Config.IncludeFields and
+// similar options:
+//
+//   - A plain name, like "Password", matches fields with that name anywhere.
+//   - A pattern between slashes, like "/Secret|Token/", is a regular expression matched against
+//     the path. Invalid regular expressions match nothing.
+//   - Anything else is a path, where * matches any run of characters, like "*.Password" or
+//     "Users[*].Token".
+//
+// Paths are relative to the graphed value, so the field Token of the elements of the slice in
+// the field Users of the graphed value is at path "Users[0].Token", "Users[1].Token" and so on.
+type pathPattern struct {
+	name string
+	re   *regexp.Regexp
+	// For paths with *, the part before the first *.
+	glob   bool
+	prefix string
+}
+
+func compilePatterns(ps []string) []pathPattern {
+	var compiled []pathPattern
+	for _, p := range ps {
+		switch {
+		case len(p) >= 2 && strings.HasPrefix(p, "/") && strings.HasSuffix(p, "/"):
+			re, err := regexp.Compile(p[1 : len(p)-1])
+			if err != nil {
+				continue
+			}
+			compiled = append(compiled, pathPattern{re: re})
+		case !strings.ContainsAny(p, ".[*"):
+			compiled = append(compiled, pathPattern{name: p})
+		default:
+			parts := strings.Split(p, "*")
+			prefix := parts[0]
+			for i := range parts {
+				parts[i] = regexp.QuoteMeta(parts[i])
+			}
+			compiled = append(compiled, pathPattern{
+				re:     regexp.MustCompile("^" + strings.Join(parts, ".*") + "$"),
+				glob:   true,
+				prefix: prefix,
+			})
+		}
+	}
+	return compiled
+}
+
+func (p pathPattern) match(name, relPath string) bool {
+	if p.re == nil {
+		return name == p.name
+	}
+	return p.re.MatchString(relPath)
+}
+
+// mayMatchBelow reports whether p may match a value inside the value at relPath.
+func (p pathPattern) mayMatchBelow(relPath string) bool {
+	if !p.glob {
+		return true
+	}
+	return strings.HasPrefix(relPath, p.prefix) ||
+		strings.HasPrefix(p.prefix, relPath+".") ||
+		strings.HasPrefix(p.prefix, relPath+"[")
+}
+
+func matchAny(ps []pathPattern, name, relPath string) bool {
+	for _, p := range ps {
+		if p.match(name, relPath) {
+			return true
+		}
+	}
+	return false
+}
+
+// relPath returns path relative to the graphed value.
+func (g *Graph) relPath(path string) string {
+	return strings.TrimPrefix(strings.TrimPrefix(path, g.root), ".")
+}
+
+// keepField reports whether the struct field with the given name, path and value is to be
+// graphed according to Config.IncludeFields and Config.ExcludeFields.
+func (g *Graph) keepField(name, path string, v reflect.Value) bool {
+	rel := g.relPath(path)
+	if matchAny(g.exclude, name, rel) {
+		return false
+	}
+	if len(g.include) == 0 {
+		return true
+	}
+
+	// Included if it or any of the fields it's inside of matches.
+	for _, prefix := range pathPrefixes(rel) {
+		if matchAny(g.include, lastName(prefix), prefix) {
+			return true
+		}
+	}
+
+	// Or if something inside it might.
+	switch v.Kind() {
+	case reflect.Array, reflect.Interface, reflect.Map, reflect.Ptr, reflect.Slice, reflect.Struct:
+		for _, p := range g.include {
+			if p.mayMatchBelow(rel) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// pathPrefixes returns the paths of the values containing the value at path, and path itself,
+// like "A", "A.B", "A.B[0]" for "A.B[0]".
+func pathPrefixes(path string) []string {
+	var prefixes []string
+	for i := 1; i < len(path); i++ {
+		if path[i] == '.' || path[i] == '[' {
+			prefixes = append(prefixes, path[:i])
+		}
+	}
+	return append(prefixes, path)
+}
+
+// lastName returns the last field name in path, or "" if it doesn't end in a field.
+func lastName(path string) string {
+	i := strings.LastIndexAny(path, ".]")
+	if i >= 0 && path[i] == ']' {
+		return ""
+	}
+	return path[i+1:]
+}
+
+// hidden reports whether the field value v is left out per Config.HideZero and Config.HideNil.
+func (g *Graph) hidden(v reflect.Value) bool {
+	if g.cfg.HideZero {
+		return v.IsZero()
+	}
+	if g.cfg.HideNil {
+		switch v.Kind() {
+		case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Ptr, reflect.Slice:
+			return v.IsNil()
+		}
+	}
+	return false
+}
@@ -0,0 +1,52 @@
+package valuegraph
+
+import "testing"
+
+func TestPathPattern(t *testing.T) {
+	for _, tc := range []struct {
+		pattern       string
+		name, relPath string
+		match         bool
+	}{
+		{"Password", "Password", "User.Password", true},
+		{"Password", "Passwords", "User.Passwords", false},
+		{"Password", "Password", "Password", true},
+		{"/Secret|Token/", "Token", "Users[0].Token", true},
+		{"/Secret|Token/", "Name", "Users[0].Name", false},
+		{"/^Token$/", "Token", "Users[0].Token", false},
+		{"/[/", "x", "[", false},
+		{"*.Password", "Password", "User.Password", true},
+		{"*.Password", "Password", "Password", false},
+		{"Users[*].Token", "Token", "Users[3].Token", true},
+		{"Users[*].Token", "Token", "Admins[3].Token", false},
+		{"User.Name", "Name", "User.Name", true},
+		{"User.Name", "Name", "Owner.User.Name", false},
+		{"a.b", "b", "axb", false},
+	} {
+		ps := compilePatterns([]string{tc.pattern})
+		if got := matchAny(ps, tc.name, tc.relPath); got != tc.match {
+			t.Errorf("%q matching %q at %q = %v, want %v", tc.pattern, tc.name, tc.relPath, got, tc.match)
+		}
+	}
+}
+
+func TestPathPatternMayMatchBelow(t *testing.T) {
+	for _, tc := range []struct {
+		pattern, relPath string
+		may              bool
+	}{
+		{"Password", "Anything", true},
+		{"/Token/", "Anything", true},
+		{"Users[*].Token", "Users", true},
+		{"Users[*].Token", "Users[0]", true},
+		{"Users[*].Token", "Admins", false},
+		{"User.Creds.*", "User", true},
+		{"User.Creds.*", "User.Creds.Key", true},
+		{"User.Creds.*", "Owner", false},
+	} {
+		p := compilePatterns([]string{tc.pattern})[0]
+		if got := p.mayMatchBelow(tc.relPath); got != tc.may {
+			t.Errorf("%q below %q = %v, want %v", tc.pattern, tc.relPath, got, tc.may)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package valuegraph
+
+import (
+	"reflect"
+	"runtime"
+	"sync"
+)
+
+// finalizers holds the name of the finalizer function of objects set with SetFinalizer, by
+// address.
+var finalizers = struct {
+	sync.Mutex
+	m map[uintptr]string
+}{m: make(map[uintptr]string)}
+
+// SetFinalizer is like runtime.SetFinalizer, but graphs show which objects have finalizers
+// attached: they get a badge node naming the finalizer, for debugging lifecycle issues.
+// Finalizers set or cleared with runtime.SetFinalizer directly aren't known.
+func SetFinalizer(obj interface{}, finalizer interface{}) {
+	addr := reflect.ValueOf(obj).Pointer()
+	if finalizer == nil {
+		finalizers.Lock()
+		delete(finalizers.m, addr)
+		finalizers.Unlock()
+		runtime.SetFinalizer(obj, nil)
+		return
+	}
+
+	fv := reflect.ValueOf(finalizer)
+	name := "(unknown)"
+	if f := runtime.FuncForPC(fv.Pointer()); f != nil {
+		name = f.Name()
+	}
+	wrapped := reflect.MakeFunc(fv.Type(), func(args []reflect.Value) []reflect.Value {
+		finalizers.Lock()
+		delete(finalizers.m, addr)
+		finalizers.Unlock()
+		return fv.Call(args)
+	})
+
+	finalizers.Lock()
+	finalizers.m[addr] = name
+	finalizers.Unlock()
+	runtime.SetFinalizer(obj, wrapped.Interface())
+}
+
+// finalizerOf returns the name of the finalizer set with SetFinalizer on the object at addr.
+func finalizerOf(addr uintptr) (string, bool) {
+	finalizers.Lock()
+	defer finalizers.Unlock()
+	name, ok := finalizers.m[addr]
+	return name, ok
+}
+
+// addFinalizerBadge adds a badge to node, the node of the object pointed to by the pointer v,
+// if it has a finalizer set with SetFinalizer.
+func (g *Graph) addFinalizerBadge(node string, v reflect.Value) {
+	name, ok := finalizerOf(v.Pointer())
+	if !ok {
+		return
+	}
+	badge := g.nextNode()
+	g.addNode(node, badge, map[string]string{
+		"label": quote("finalizer\n" + name),
+		"shape": "octagon",
+	})
+	g.addEdge(node, badge, map[string]string{"style": "dotted", "arrowhead": "none"})
+}
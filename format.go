@@ -0,0 +1,70 @@
+package valuegraph
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// A Formatter returns the label of a value, shown after its type, instead of walking into it.
+type Formatter func(v reflect.Value) string
+
+type ifaceFormatter struct {
+	iface reflect.Type
+	f     Formatter
+}
+
+// RegisterFormatter makes values of type t graphed as a single node labeled by f, like UUIDs
+// as their canonical string instead of an array of bytes. Formatters take precedence over any
+// other way of graphing a value. f is only called with values whose Interface method can be
+// called; if it panics, the node says so.
+func (c *Config) RegisterFormatter(t reflect.Type, f Formatter) {
+	formatters := make(map[reflect.Type]Formatter, len(c.formatters)+1)
+	for t, f := range c.formatters {
+		formatters[t] = f
+	}
+	formatters[t] = f
+	c.formatters = formatters
+}
+
+// RegisterInterfaceFormatter is like RegisterFormatter, for values of any type implementing
+// the interface type iface, like reflect.TypeOf((*proto.Message)(nil)).Elem(). Formatters for
+// specific types are checked first, then interface formatters in the order they were
+// registered.
+func (c *Config) RegisterInterfaceFormatter(iface reflect.Type, f Formatter) {
+	// Don't share the backing array with copies of c.
+	c.ifaceFormatters = append(c.ifaceFormatters[:len(c.ifaceFormatters):len(c.ifaceFormatters)], ifaceFormatter{iface, f})
+}
+
+// formatter returns the registered formatter for values of type t, if any.
+func (g *Graph) formatter(t reflect.Type) Formatter {
+	if f, ok := g.cfg.formatters[t]; ok {
+		return f
+	}
+	if t.Kind() == reflect.Interface {
+		return nil
+	}
+	for _, f := range g.cfg.ifaceFormatters {
+		if t.Implements(f.iface) {
+			return f.f
+		}
+	}
+	return nil
+}
+
+// format labels v with its registered formatter, if any.
+func (g *Graph) format(v reflect.Value) (s string, ok bool) {
+	f := g.formatter(v.Type())
+	if f == nil {
+		return "", false
+	}
+	x, ok := g.exposed(v)
+	if !ok {
+		return "", false
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			s, ok = fmt.Sprintf("\n(formatter panicked: %v)", r), true
+		}
+	}()
+	return "\n" + f(x), true
+}
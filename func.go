@@ -0,0 +1,65 @@
+package valuegraph
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// funcLabel returns the rest of the label of the function v: its signature, if its type is
+// named, and the name and location of the function, or of the closure's literal.
+func (g *Graph) funcLabel(v reflect.Value) string {
+	label := ""
+	if ty := v.Type(); ty.Name() != "" {
+		// Unnamed types are already shown as their signature.
+		label += `\n` + escape("func"+signature(ty, 0))
+	}
+	if v.IsNil() {
+		return label + `: <nil>`
+	}
+	f := runtime.FuncForPC(v.Pointer())
+	if f == nil {
+		return label + `\n(unknown function)`
+	}
+	name := f.Name()
+	if strings.HasSuffix(name, "-fm") {
+		// Method values are wrapped by generated functions with no location of their own.
+		return label + `\n` + escape(strings.TrimSuffix(name, "-fm")) + `\n(method value)`
+	}
+	file, line := f.FileLine(f.Entry())
+	return label + `\n` + escape(name) + `\n` + escape(fmt.Sprintf("%v:%v", filepath.Base(file), line))
+}
+
+// signature returns the parameters and results of the func type ty, as written after func in
+// its type literal, leaving out the first skip parameters, like the receiver of methods.
+func signature(ty reflect.Type, skip int) string {
+	s := "("
+	for i := skip; i < ty.NumIn(); i++ {
+		if i > skip {
+			s += ", "
+		}
+		if ty.IsVariadic() && i == ty.NumIn()-1 {
+			s += "..." + ty.In(i).Elem().String()
+		} else {
+			s += ty.In(i).String()
+		}
+	}
+	s += ")"
+	switch ty.NumOut() {
+	case 0:
+	case 1:
+		s += " " + ty.Out(0).String()
+	default:
+		s += " ("
+		for i := 0; i < ty.NumOut(); i++ {
+			if i > 0 {
+				s += ", "
+			}
+			s += ty.Out(i).String()
+		}
+		s += ")"
+	}
+	return s
+}
@@ -0,0 +1,136 @@
+// Package gallery generates static HTML galleries of graphs of example values, as visual
+// documentation of a codebase's data structures.
+//
+// Galleries are table-driven: list the examples in a small program, like
+//
+//	err := gallery.Write("docs/gallery", valuegraph.DefaultConfig, []gallery.Example{
+//		{Name: "Empty tree", Source: "tree.New()", Value: tree.New()},
+//		{Name: "Balanced tree", Source: "tree.New(1, 2, 3)", Value: tree.New(1, 2, 3)},
+//	})
+//
+// or use the valuegraph-gallery command for a JSON corpus.
+package gallery
+
+import (
+	"html/template"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/tcard/valuegraph"
+)
+
+// An Example is a value shown in a gallery.
+type Example struct {
+	// Title of the example, also used to name its files.
+	Name string
+	// Source snippet shown next to the graph, like the expression the value comes from.
+	// Optional.
+	Source string
+	// The value to graph.
+	Value interface{}
+}
+
+// Write renders the examples as SVG files in dir, along with an index.html page showing each
+// with its name and source snippet. dir is created if needed. A nil c means
+// valuegraph.DefaultConfig. It requires the dot command to be available in the system.
+func Write(dir string, c *valuegraph.Config, examples []Example) error {
+	return WriteFormat(dir, c, "svg", examples)
+}
+
+// imageFormats are the formats browsers show as images. Examples in other formats are linked
+// from the page instead.
+var imageFormats = map[string]bool{"svg": true, "png": true, "gif": true, "jpg": true}
+
+// WriteFormat is like Write, but writes the examples with the exporter registered for format,
+// as accepted by Graph.Export.
+func WriteFormat(dir string, c *valuegraph.Config, format string, examples []Example) error {
+	if c == nil {
+		c = valuegraph.DefaultConfig
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	type entry struct {
+		Example
+		ID    string
+		File  string
+		Image bool
+	}
+	entries := make([]entry, len(examples))
+	used := make(map[string]bool)
+	for i, ex := range examples {
+		id := slug(ex.Name)
+		for n := 2; used[id]; n++ {
+			id = slug(ex.Name) + "-" + strconv.Itoa(n)
+		}
+		used[id] = true
+
+		file := id + "." + format
+		if err := export(filepath.Join(dir, file), c.Make(ex.Value), format); err != nil {
+			return err
+		}
+		entries[i] = entry{ex, id, file, imageFormats[format]}
+	}
+
+	f, err := os.Create(filepath.Join(dir, "index.html"))
+	if err != nil {
+		return err
+	}
+	if err := page.Execute(f, entries); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func export(path string, g *valuegraph.Graph, format string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := g.Export(format, f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+var nonSlug = regexp.MustCompile(`[^a-z0-9]+`)
+
+// slug makes name suitable for file names and HTML ids.
+func slug(name string) string {
+	s := strings.Trim(nonSlug.ReplaceAllString(strings.ToLower(name), "-"), "-")
+	if s == "" {
+		return "example"
+	}
+	return s
+}
+
+var page = template.Must(template.New("gallery").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>valuegraph gallery</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+nav a { margin-right: 1em; }
+section { margin: 2em 0; border-top: 1px solid #ccc; }
+pre { background: #f5f5f5; padding: 1em; overflow-x: auto; }
+img { max-width: 100%; }
+</style>
+</head>
+<body>
+<h1>valuegraph gallery</h1>
+<nav>{{range .}}<a href="#{{.ID}}">{{.Name}}</a>{{end}}</nav>
+{{range .}}<section id="{{.ID}}">
+<h2>{{.Name}}</h2>
+{{if .Source}}<pre><code>{{.Source}}</code></pre>
+{{end}}{{if .Image}}<img src="{{.File}}" alt="{{.Name}}">{{else}}<a href="{{.File}}">{{.File}}</a>{{end}}
+</section>
+{{end}}</body>
+</html>
+`))
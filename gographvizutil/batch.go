@@ -0,0 +1,178 @@
+package gographvizutil
+
+import (
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/awalterschulze/gographviz"
+)
+
+// RenderBatch renders several graphs with a single dot process. Only SVG, PNG and Plain are
+// supported, since the outputs of other formats can't be reliably told apart.
+// It requires the dot command to be available in the system.
+func RenderBatch(gs []*gographviz.Graph, layout Layout, fmt Format) ([]string, error) {
+	if !batchable(fmt) {
+		return nil, errors.New("gographvizutil: format " + string(fmt) + " can't be rendered in batches")
+	}
+	var in strings.Builder
+	for _, g := range gs {
+		in.WriteString(g.String())
+		in.WriteString("\n")
+	}
+	out, err := render(in.String(), layout, fmt)
+	if err != nil {
+		return nil, err
+	}
+	outs := splitOutputs(out, fmt)
+	if len(outs) != len(gs) {
+		return nil, errors.New("gographvizutil: dot produced an unexpected number of outputs")
+	}
+	return outs, nil
+}
+
+func batchable(fmt Format) bool {
+	return fmt == SVG || fmt == PNG || fmt == Plain
+}
+
+func splitOutputs(out string, fmt Format) []string {
+	var outs []string
+	switch fmt {
+	case Plain:
+		const end = "stop\n"
+		for {
+			i := strings.Index(out, end)
+			if i < 0 {
+				break
+			}
+			outs = append(outs, out[:i+len(end)])
+			out = out[i+len(end):]
+		}
+		return outs
+	case SVG:
+		return splitBefore(out, "<?xml")
+	case PNG:
+		return splitBefore(out, "\x89PNG\r\n\x1a\n")
+	}
+	return nil
+}
+
+func splitBefore(out, start string) []string {
+	var outs []string
+	for len(out) > 0 {
+		i := strings.Index(out[1:], start)
+		if i < 0 {
+			outs = append(outs, out)
+			break
+		}
+		outs = append(outs, out[:i+1])
+		out = out[i+1:]
+	}
+	return outs
+}
+
+// A Batcher is a Renderer that collects concurrent renders and runs them through as few dot
+// processes as possible, for services rendering many graphs per second. Formats that can't be
+// batched are rendered one by one. It is safe for concurrent use.
+type Batcher struct {
+	max  int
+	reqs chan *batchReq
+	done chan struct{}
+	once sync.Once
+}
+
+type batchReq struct {
+	g      *gographviz.Graph
+	layout Layout
+	fmt    Format
+	out    string
+	err    error
+	ready  chan struct{}
+}
+
+// NewBatcher returns a Batcher that renders up to max graphs per dot process.
+// Close it to stop its background goroutine.
+func NewBatcher(max int) *Batcher {
+	if max < 1 {
+		max = 1
+	}
+	b := &Batcher{
+		max:  max,
+		reqs: make(chan *batchReq),
+		done: make(chan struct{}),
+	}
+	go b.loop()
+	return b
+}
+
+// Render queues the graph for rendering and waits for the result.
+func (b *Batcher) Render(g *gographviz.Graph, layout Layout, fmt Format) (string, error) {
+	if !batchable(fmt) {
+		return RenderLayout(g, layout, fmt)
+	}
+	r := &batchReq{g: g, layout: layout, fmt: fmt, ready: make(chan struct{})}
+	select {
+	case b.reqs <- r:
+	case <-b.done:
+		return RenderLayout(g, layout, fmt)
+	}
+	<-r.ready
+	return r.out, r.err
+}
+
+// Close stops the Batcher. Renders after Close run dot directly. Calling it more than once
+// does nothing.
+func (b *Batcher) Close() {
+	b.once.Do(func() { close(b.done) })
+}
+
+func (b *Batcher) loop() {
+	for {
+		var batch []*batchReq
+		select {
+		case r := <-b.reqs:
+			batch = append(batch, r)
+		case <-b.done:
+			return
+		}
+	collect:
+		for len(batch) < b.max {
+			select {
+			case r := <-b.reqs:
+				batch = append(batch, r)
+			default:
+				break collect
+			}
+		}
+		go b.run(batch)
+	}
+}
+
+type batchKey struct {
+	layout Layout
+	fmt    Format
+}
+
+func (b *Batcher) run(batch []*batchReq) {
+	groups := make(map[batchKey][]*batchReq)
+	for _, r := range batch {
+		k := batchKey{r.layout, r.fmt}
+		groups[k] = append(groups[k], r)
+	}
+	for k, rs := range groups {
+		gs := make([]*gographviz.Graph, len(rs))
+		for i, r := range rs {
+			gs[i] = r.g
+		}
+		outs, err := RenderBatch(gs, k.layout, k.fmt)
+		for i, r := range rs {
+			if err != nil {
+				// Don't let one bad graph fail the whole batch.
+				r.out, r.err = RenderLayout(r.g, k.layout, k.fmt)
+			} else {
+				r.out = outs[i]
+			}
+			close(r.ready)
+		}
+	}
+}
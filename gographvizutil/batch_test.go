@@ -0,0 +1,35 @@
+package gographvizutil
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitOutputs(t *testing.T) {
+	const png = "\x89PNG\r\n\x1a\n"
+	for _, tc := range []struct {
+		out  string
+		fmt  Format
+		want []string
+	}{
+		{"", Plain, nil},
+		{"graph 1 1 1\nstop\n", Plain, []string{"graph 1 1 1\nstop\n"}},
+		{"graph 1\nstop\ngraph 2\nstop\n", Plain, []string{"graph 1\nstop\n", "graph 2\nstop\n"}},
+		{"graph 1\nstop\ntrailing", Plain, []string{"graph 1\nstop\n"}},
+		{"", SVG, nil},
+		{"<?xml a?><svg/>", SVG, []string{"<?xml a?><svg/>"}},
+		{"<?xml a?><svg/>\n<?xml b?><svg/>\n", SVG, []string{"<?xml a?><svg/>\n", "<?xml b?><svg/>\n"}},
+		{png + "one" + png + "two", PNG, []string{png + "one", png + "two"}},
+		{"anything", PDF, nil},
+	} {
+		if got := splitOutputs(tc.out, tc.fmt); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("splitOutputs(%q, %v) = %q, want %q", tc.out, tc.fmt, got, tc.want)
+		}
+	}
+}
+
+func TestBatcherCloseTwice(t *testing.T) {
+	b := NewBatcher(4)
+	b.Close()
+	b.Close()
+}
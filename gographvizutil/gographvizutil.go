@@ -2,9 +2,13 @@ package gographvizutil
 
 import (
 	"bytes"
+	"container/list"
+	"crypto/sha256"
 	"errors"
 	"os/exec"
+	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/awalterschulze/gographviz"
 )
@@ -16,25 +20,204 @@ const (
 	SVG        Format = "svg"
 	PNG        Format = "png"
 	GIF        Format = "gif"
+	JPEG       Format = "jpg"
 	PDF        Format = "pdf"
 	PostScript Format = "ps"
+	Plain      Format = "plain"
+)
+
+// A Layout is a Graphviz layout engine, as accepted by the -K flag of the dot command.
+type Layout string
+
+const (
+	Dot   Layout = "dot"
+	Neato Layout = "neato"
+	Fdp   Layout = "fdp"
+	Sfdp  Layout = "sfdp"
+	Twopi Layout = "twopi"
+	Circo Layout = "circo"
 )
 
 var ErrNoDot = errors.New("cannot find dot installed in the system")
 
+// IsDotAvailable reports whether the dot command is available in the system.
+func IsDotAvailable() bool {
+	_, err := exec.LookPath("dot")
+	return err == nil
+}
+
+// A RenderError is returned when the dot command runs but fails, typically because of
+// malformed input or an unsupported format.
+type RenderError struct {
+	Err    error
+	Stderr string
+}
+
+func (e *RenderError) Error() string {
+	if e.Stderr == "" {
+		return "dot: " + e.Err.Error()
+	}
+	return "dot: " + e.Err.Error() + ": " + strings.TrimSpace(e.Stderr)
+}
+
+func (e *RenderError) Unwrap() error {
+	return e.Err
+}
+
 // Render turns *github.com/awalterschulze/gographviz.Graph into the desired format.
 // It requires the dot command to be available in the system.
 func Render(g *gographviz.Graph, fmt Format) (string, error) {
-	if _, err := exec.LookPath("dot"); err != nil {
+	return RenderLayout(g, Dot, fmt)
+}
+
+// RenderLayout is like Render, but lays out the graph with the given engine instead of dot.
+// An empty layout means Dot.
+func RenderLayout(g *gographviz.Graph, layout Layout, fmt Format) (string, error) {
+	return render(g.String(), layout, fmt)
+}
+
+func render(dot string, layout Layout, fmt Format) (string, error) {
+	if !IsDotAvailable() {
 		return "", ErrNoDot
 	}
+	if layout == "" {
+		layout = Dot
+	}
 
-	cmd := exec.Command("dot", "-T"+string(fmt))
+	cmd := exec.Command("dot", "-K"+string(layout), "-T"+string(fmt))
 	var outBuf, errBuf bytes.Buffer
-	cmd.Stdin, cmd.Stdout, cmd.Stderr = strings.NewReader(g.String()), &outBuf, &errBuf
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = strings.NewReader(dot), &outBuf, &errBuf
 	if err := cmd.Run(); err != nil {
-		return "", err
+		return "", &RenderError{Err: err, Stderr: errBuf.String()}
 	}
 
 	return outBuf.String(), nil
 }
+
+// RenderFiles renders the graph into several formats with a single dot process, writing each
+// format to the file at the path it's mapped to. An empty layout means Dot.
+// It requires the dot command to be available in the system.
+func RenderFiles(g *gographviz.Graph, layout Layout, files map[Format]string) error {
+	if !IsDotAvailable() {
+		return ErrNoDot
+	}
+	if layout == "" {
+		layout = Dot
+	}
+
+	args := []string{"-K" + string(layout)}
+	for f, path := range files {
+		args = append(args, "-T"+string(f), "-o"+path)
+	}
+	cmd := exec.Command("dot", args...)
+	var errBuf bytes.Buffer
+	cmd.Stdin, cmd.Stderr = strings.NewReader(g.String()), &errBuf
+	if err := cmd.Run(); err != nil {
+		return &RenderError{Err: err, Stderr: errBuf.String()}
+	}
+	return nil
+}
+
+// A Position is the location of a node's center in a laid out graph, in inches.
+type Position struct {
+	X, Y float64
+}
+
+// Positions lays out the graph with the given engine and returns the position of each node,
+// keyed by node name. An empty layout means Dot.
+// It requires the dot command to be available in the system.
+func Positions(g *gographviz.Graph, layout Layout) (map[string]Position, error) {
+	out, err := RenderLayout(g, layout, Plain)
+	if err != nil {
+		return nil, err
+	}
+
+	pos := make(map[string]Position)
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 4 || fields[0] != "node" {
+			continue
+		}
+		x, err := strconv.ParseFloat(fields[2], 64)
+		if err != nil {
+			return nil, err
+		}
+		y, err := strconv.ParseFloat(fields[3], 64)
+		if err != nil {
+			return nil, err
+		}
+		pos[strings.Trim(fields[1], `"`)] = Position{x, y}
+	}
+	return pos, nil
+}
+
+// A Renderer turns graphs into the desired format with the given layout engine.
+// RenderLayout is the default; Cache and Batcher wrap or replace it.
+type Renderer interface {
+	Render(g *gographviz.Graph, layout Layout, fmt Format) (string, error)
+}
+
+// A Cache keeps the most recently rendered outputs keyed by a hash of the graph, layout and
+// format, so that rendering an identical graph again doesn't run dot.
+// It is safe for concurrent use.
+type Cache struct {
+	mu    sync.Mutex
+	size  int
+	next  Renderer
+	ll    *list.List
+	items map[[sha256.Size]byte]*list.Element
+}
+
+type cacheEntry struct {
+	key [sha256.Size]byte
+	out string
+}
+
+// NewCache returns a Cache holding up to size rendered outputs. On a miss, it renders with next,
+// or with RenderLayout if next is nil.
+func NewCache(size int, next Renderer) *Cache {
+	return &Cache{
+		size:  size,
+		next:  next,
+		ll:    list.New(),
+		items: make(map[[sha256.Size]byte]*list.Element),
+	}
+}
+
+// Render is like RenderLayout, but reuses the output of a previous call for an identical graph.
+// Errors are not cached.
+func (c *Cache) Render(g *gographviz.Graph, layout Layout, fmt Format) (string, error) {
+	dot := g.String()
+	key := sha256.Sum256([]byte(string(layout) + "\x00" + string(fmt) + "\x00" + dot))
+
+	c.mu.Lock()
+	if e, ok := c.items[key]; ok {
+		c.ll.MoveToFront(e)
+		c.mu.Unlock()
+		return e.Value.(*cacheEntry).out, nil
+	}
+	c.mu.Unlock()
+
+	var out string
+	var err error
+	if c.next != nil {
+		out, err = c.next.Render(g, layout, fmt)
+	} else {
+		out, err = render(dot, layout, fmt)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.items[key]; !ok && c.size > 0 {
+		c.items[key] = c.ll.PushFront(&cacheEntry{key, out})
+		for c.ll.Len() > c.size {
+			e := c.ll.Back()
+			c.ll.Remove(e)
+			delete(c.items, e.Value.(*cacheEntry).key)
+		}
+	}
+	return out, nil
+}
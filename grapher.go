@@ -0,0 +1,63 @@
+package valuegraph
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// A Grapher is a value that controls how it's graphed, like json.Marshaler does for
+// encoding/json. GraphValue returns the label of its node, shown after its type, and the
+// values to graph as its children, by name, instead of walking into the value itself.
+//
+// If GraphValue panics, the node says so.
+type Grapher interface {
+	GraphValue() (label string, children map[string]interface{})
+}
+
+var grapherType = reflect.TypeOf((*Grapher)(nil)).Elem()
+
+// graphValue graphs v with its GraphValue method, if it's a Grapher, adding its children to
+// node. It returns the rest of the label of the node, escaped for DOT.
+func (g *Graph) graphValue(node string, v reflect.Value, depth int, limit int, path string) (string, bool) {
+	if !v.Type().Implements(grapherType) {
+		if !v.CanAddr() || !reflect.PtrTo(v.Type()).Implements(grapherType) {
+			return "", false
+		}
+		v = v.Addr()
+	}
+	if (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && v.IsNil() {
+		return "", false
+	}
+	x, ok := g.exposed(v)
+	if !ok {
+		return "", false
+	}
+
+	label, children, err := callGraphValue(x.Interface().(Grapher))
+	if err != nil {
+		return escape("\n(" + err.Error() + ")"), true
+	}
+	names := make([]string, 0, len(children))
+	for name := range children {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		g.addValue(node, name, reflect.ValueOf(children[name]), depth+1, limit, nil, path+"."+name)
+	}
+	if label == "" {
+		return "", true
+	}
+	return escape("\n" + label), true
+}
+
+func callGraphValue(gr Grapher) (label string, children map[string]interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("GraphValue panicked: %v", r)
+		}
+	}()
+	label, children = gr.GraphValue()
+	return label, children, nil
+}
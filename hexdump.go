@@ -0,0 +1,37 @@
+package valuegraph
+
+import (
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// hexdump returns the rest of the label of byte slices and arrays, as a hexdump with an ASCII
+// column, per Config.HexdumpLimit. The result is escaped for DOT, with left-justified lines.
+func (g *Graph) hexdump(v reflect.Value, path string) (string, bool) {
+	if g.cfg.HexdumpLimit <= 0 || v.Type().Elem().Kind() != reflect.Uint8 {
+		return "", false
+	}
+	n := v.Len()
+	if n > g.cfg.HexdumpLimit {
+		n = g.cfg.HexdumpLimit
+	}
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte(v.Index(i).Uint())
+	}
+
+	var s strings.Builder
+	s.WriteString(`\n`)
+	for _, line := range strings.SplitAfter(hex.Dump(b), "\n") {
+		if line = strings.TrimSuffix(line, "\n"); line != "" {
+			s.WriteString(escape(line) + `\l`)
+		}
+	}
+	if more := v.Len() - n; more > 0 {
+		g.truncate(path, "hexdump limit reached", "limit", g.cfg.HexdumpLimit, "omitted", more)
+		s.WriteString(escape(fmt.Sprintf("... %v more bytes", more)) + `\l`)
+	}
+	return s.String(), true
+}
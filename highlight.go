@@ -0,0 +1,17 @@
+package valuegraph
+
+import "reflect"
+
+// Color of the border of nodes highlighted per Config.Highlight.
+const highlightColor = "#e6194b"
+
+// highlight marks the node with the given attributes, of the value v at path, if
+// Config.Highlight matches it.
+func (g *Graph) highlight(path string, v reflect.Value, attrs map[string]string) {
+	if g.cfg.Highlight == nil || !v.IsValid() || !g.cfg.Highlight(path, v) {
+		return
+	}
+	attrs["color"] = quote(highlightColor)
+	attrs["penwidth"] = "3"
+	g.debug("highlighted", path)
+}
@@ -0,0 +1,74 @@
+package valuegraph
+
+import "reflect"
+
+// A NodeInfo is a node for a value about to be added to the graph, passed to Config.OnNode,
+// which can change its label and attributes.
+type NodeInfo struct {
+	// Path and depth of the value, and the value itself.
+	Path  string
+	Depth int
+	Value reflect.Value
+	// Text of the label, with line breaks as newlines.
+	Label string
+	// Graphviz attributes of the node other than label, by name. Values must be quoted as
+	// needed, like `"#ff0000"`.
+	Attrs map[string]string
+}
+
+// An EdgeInfo is an edge about to be added to the graph, passed to Config.OnEdge, which can
+// change its attributes.
+type EdgeInfo struct {
+	// Paths of the values the edge goes from and to. Empty for nodes that aren't values, like
+	// summaries of elements left out.
+	From, To string
+	// Graphviz attributes of the edge, by name, quoted as in NodeInfo.
+	Attrs map[string]string
+}
+
+// skipped reports whether Config.Skip vetoes walking into the value v at path.
+func (g *Graph) skipped(path string, depth int, v reflect.Value) bool {
+	return g.cfg.Skip != nil && v.IsValid() && g.cfg.Skip(path, depth, v)
+}
+
+// onNode passes the node for the value v at path, with the given DOT-escaped label and
+// attributes, through Config.OnNode, and sets its label.
+func (g *Graph) onNode(path string, depth int, v reflect.Value, label string, attrs map[string]string) {
+	if g.cfg.OnNode == nil {
+		attrs["label"] = `"` + label + `"`
+		return
+	}
+	text := unquote(`"` + label + `"`)
+	n := &NodeInfo{Path: path, Depth: depth, Value: v, Label: text, Attrs: make(map[string]string, len(attrs))}
+	for k, a := range attrs {
+		n.Attrs[k] = a
+	}
+	g.cfg.OnNode(n)
+	for k := range attrs {
+		delete(attrs, k)
+	}
+	for k, a := range n.Attrs {
+		attrs[k] = a
+	}
+	// unquote loses left- and right-justified line breaks, so labels are only quoted again if
+	// the hook changed them.
+	if n.Label == text {
+		attrs["label"] = `"` + label + `"`
+	} else {
+		attrs["label"] = quote(n.Label)
+	}
+}
+
+// onEdge passes an edge from node src to node dst through Config.OnEdge, returning its
+// attributes.
+func (g *Graph) onEdge(src, dst string, attrs map[string]string) map[string]string {
+	if g.cfg.OnEdge == nil {
+		return attrs
+	}
+	e := &EdgeInfo{From: g.paths[src], To: g.paths[dst], Attrs: make(map[string]string, len(attrs))}
+	for k, a := range attrs {
+		e.Attrs[k] = a
+	}
+	g.cfg.OnEdge(e)
+	return e.Attrs
+}
@@ -0,0 +1,28 @@
+package valuegraph
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestOnNodeNoopKeepsLabels(t *testing.T) {
+	v := []byte("a byte slice long enough to need a few hexdump lines")
+	c := *DefaultConfig
+	want := c.Make(v).Dot()
+	if !strings.Contains(want, `\l`) {
+		t.Fatalf("hexdump label has no left-justified lines:\n%s", want)
+	}
+
+	c.OnNode = func(n *NodeInfo) {}
+	if got := c.Make(v).Dot(); got != want {
+		t.Errorf("no-op OnNode changed the graph\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestOnNodeChangesLabel(t *testing.T) {
+	c := *DefaultConfig
+	c.OnNode = func(n *NodeInfo) { n.Label += "\nchanged" }
+	if got := c.Make(1).Dot(); !strings.Contains(got, `int: 1\nchanged`) {
+		t.Errorf("label not changed by OnNode:\n%s", got)
+	}
+}
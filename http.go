@@ -0,0 +1,101 @@
+package valuegraph
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Headers beyond this many aren't shown in HTTP requests and responses, and header values
+// are cut to this many bytes.
+const (
+	maxHTTPHeaders    = 10
+	maxHTTPHeaderSize = 60
+)
+
+// Headers whose values are shown as «redacted», since they usually hold credentials.
+var redactedHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+	"Set-Cookie":          true,
+}
+
+var (
+	httpRequestType  = reflect.TypeOf(http.Request{})
+	httpResponseType = reflect.TypeOf(http.Response{})
+)
+
+func renderHTTPRequest(_ *Config, v reflect.Value) string {
+	r := addressable(v).Addr().Interface().(*http.Request)
+	var b strings.Builder
+	b.WriteString("\n" + requestLine(r) + " " + r.Proto)
+	writeHeaders(&b, r.Header)
+	writeBodyLength(&b, r.ContentLength)
+	return b.String()
+}
+
+func renderHTTPResponse(_ *Config, v reflect.Value) string {
+	r := addressable(v).Addr().Interface().(*http.Response)
+	var b strings.Builder
+	b.WriteString("\n" + r.Proto + " " + r.Status)
+	if r.Request != nil {
+		b.WriteString("\nto " + requestLine(r.Request))
+	}
+	writeHeaders(&b, r.Header)
+	writeBodyLength(&b, r.ContentLength)
+	return b.String()
+}
+
+// requestLine returns the method and URL of r, without any password in the URL.
+func requestLine(r *http.Request) string {
+	method := r.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	if r.URL == nil {
+		return method
+	}
+	return method + " " + r.URL.Redacted()
+}
+
+func writeHeaders(b *strings.Builder, h http.Header) {
+	names := make([]string, 0, len(h))
+	for name := range h {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for i, name := range names {
+		if i == maxHTTPHeaders {
+			fmt.Fprintf(b, "\n... %v more headers", len(names)-i)
+			break
+		}
+		value := strings.Join(h[name], ", ")
+		if redactedHeaders[http.CanonicalHeaderKey(name)] {
+			value = "«redacted»"
+		} else if len(value) > maxHTTPHeaderSize {
+			value = value[:runeCut(value, maxHTTPHeaderSize)] + "…"
+		}
+		b.WriteString("\n" + name + ": " + value)
+	}
+}
+
+func writeBodyLength(b *strings.Builder, n int64) {
+	if n < 0 {
+		b.WriteString("\nbody: unknown length")
+		return
+	}
+	fmt.Fprintf(b, "\nbody: %v bytes", n)
+}
+
+// addressable returns v, or an addressable copy of it.
+func addressable(v reflect.Value) reflect.Value {
+	if v.CanAddr() {
+		return v
+	}
+	c := reflect.New(v.Type()).Elem()
+	c.Set(v)
+	return c
+}
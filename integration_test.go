@@ -0,0 +1,255 @@
+//go:build integration
+// +build integration
+
+package valuegraph_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"flag"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/awalterschulze/gographviz"
+	"github.com/tcard/valuegraph"
+	"github.com/tcard/valuegraph/artifactspec"
+	"github.com/tcard/valuegraph/gographvizutil"
+)
+
+// A testCase is a value graphed with a config.
+type testCase struct {
+	name  string
+	cfg   *valuegraph.Config
+	value interface{}
+}
+
+type list struct {
+	V    int
+	Next *list
+}
+
+func corpus() []testCase {
+	cyclic := &list{V: 1, Next: &list{V: 2}}
+	cyclic.Next.Next = cyclic
+
+	selfMap := map[string]interface{}{"n": 1}
+	selfMap["self"] = selfMap
+
+	selfSlice := make([]interface{}, 2)
+	selfSlice[0] = selfSlice
+	selfSlice[1] = "x"
+
+	huge := make(map[int]string, 2000)
+	for i := 0; i < 2000; i++ {
+		huge[i] = fmt.Sprint("value ", i)
+	}
+
+	var nilFunc func()
+	var nilIface error
+	nils := struct {
+		Ptr   *int
+		Map   map[string]int
+		Slice []int
+		Iface error
+		Func  func()
+		Chan  chan int
+	}{nil, nil, nil, nilIface, nilFunc, nil}
+
+	tricky := []string{
+		"héllo, 世界 🎉",
+		`"quoted" and \backslashed\`,
+		"line\nbreaks\r\nand\ttabs",
+		"<html> & {braces} [brackets] |pipes|",
+		"\x00\x01\xff invalid UTF-8",
+		strings.Repeat("long ", 200),
+	}
+
+	numbers := []interface{}{math.NaN(), math.Inf(1), math.Inf(-1), -0.0, uint64(math.MaxUint64), complex(1, -1)}
+
+	styled := *valuegraph.DefaultConfig
+	styled.Style = valuegraph.Style{
+		RankDir:  "LR",
+		Theme:    valuegraph.Dark,
+		FontName: valuegraph.MonospaceFont,
+	}
+	styled.ChainLimit = 2
+
+	long := (*list)(nil)
+	for i := 0; i < 100; i++ {
+		long = &list{V: i, Next: long}
+	}
+
+	return []testCase{
+		{"nil", valuegraph.DefaultConfig, nil},
+		{"pointer cycle", valuegraph.DefaultConfig, cyclic},
+		{"map cycle", valuegraph.DefaultConfig, selfMap},
+		{"slice cycle", valuegraph.DefaultConfig, selfSlice},
+		{"huge map", valuegraph.DefaultConfig, huge},
+		{"nils", valuegraph.DefaultConfig, nils},
+		{"strings", valuegraph.DefaultConfig, tricky},
+		{"map with tricky keys", valuegraph.DefaultConfig, map[string]string{`a"b`: `\`, "": "empty", "\n": "é"}},
+		{"numbers", valuegraph.DefaultConfig, numbers},
+		{"bytes", valuegraph.DefaultConfig, []byte("\x00binary\xffdata")},
+		{"styled", &styled, tricky},
+		{"styled chain", &styled, long},
+	}
+}
+
+// checks validate outputs by format. Formats without one only need to be non-empty.
+var checks = map[string]func(out []byte) error{
+	"dot":       checkDot,
+	"canonical": checkCanonical,
+	"json":      checkJSON,
+	"svg":       checkSVG,
+	"png":       checkImage("png"),
+	"gif":       checkImage("gif"),
+	"jpg":       checkImage("jpeg"),
+	"pdf":       checkHeader("%PDF-"),
+	"ps":        checkHeader("%!PS"),
+	"plain":     checkHeader("graph "),
+}
+
+var outDir = flag.String("integration.out", "", "directory to keep the outputs of TestExportFormats in; by default, they're discarded")
+
+// TestExportFormats renders a corpus of tricky values in every registered export format with
+// the real dot command, and checks that each output is well formed: that dot, JSON and SVG
+// outputs parse, images decode and documents have the right headers, so that rendering
+// regressions are caught before release.
+//
+// It is behind the integration build tag, since it needs Graphviz, and skipped if dot isn't
+// installed:
+//
+//	go test -tags integration -run TestExportFormats . [-args -integration.out dir]
+//
+// or, without Graphviz installed, with integration.Dockerfile, from the repository root:
+//
+//	docker build -f integration.Dockerfile .
+func TestExportFormats(t *testing.T) {
+	if !gographvizutil.IsDotAvailable() {
+		t.Skip("the dot command isn't available")
+	}
+	if *outDir != "" {
+		if err := os.MkdirAll(*outDir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, tc := range corpus() {
+		for _, format := range valuegraph.Exporters() {
+			tc, format := tc, format
+			t.Run(tc.name+"/"+format, func(t *testing.T) {
+				if err := run(tc, format, *outDir); err != nil {
+					t.Error(err)
+				}
+			})
+		}
+	}
+}
+
+func run(tc testCase, format, dir string) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panicked: %v", r)
+		}
+	}()
+	var b bytes.Buffer
+	if err := tc.cfg.Make(tc.value).Export(format, &b); err != nil {
+		return err
+	}
+	if dir != "" {
+		name := strings.Replace(tc.name, " ", "-", -1) + "." + format
+		if err := ioutil.WriteFile(filepath.Join(dir, name), b.Bytes(), 0644); err != nil {
+			return err
+		}
+	}
+	if b.Len() == 0 {
+		return errors.New("empty output")
+	}
+	if check, ok := checks[format]; ok {
+		return check(b.Bytes())
+	}
+	return nil
+}
+
+func checkDot(out []byte) error {
+	_, err := gographviz.Read(out)
+	return err
+}
+
+func checkCanonical(out []byte) error {
+	if err := checkDot(out); err != nil {
+		return err
+	}
+	again, err := valuegraph.Canonical(string(out))
+	if err != nil {
+		return err
+	}
+	if again != string(out) {
+		return errors.New("canonical output changes when canonicalized again")
+	}
+	return nil
+}
+
+func checkJSON(out []byte) error {
+	a, err := artifactspec.Decode(bytes.NewReader(out))
+	if err != nil {
+		return err
+	}
+	return checkDot([]byte(a.Dot))
+}
+
+func checkSVG(out []byte) error {
+	dec := xml.NewDecoder(bytes.NewReader(out))
+	root := ""
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if start, ok := tok.(xml.StartElement); ok && root == "" {
+			root = start.Name.Local
+		}
+	}
+	if root != "svg" {
+		return fmt.Errorf("root element is %q, not svg", root)
+	}
+	return nil
+}
+
+func checkImage(format string) func(out []byte) error {
+	return func(out []byte) error {
+		img, got, err := image.Decode(bytes.NewReader(out))
+		if err != nil {
+			return err
+		}
+		if got != format {
+			return fmt.Errorf("decoded as %v, not %v", got, format)
+		}
+		if b := img.Bounds(); b.Dx() == 0 || b.Dy() == 0 {
+			return errors.New("empty image")
+		}
+		return nil
+	}
+}
+
+func checkHeader(prefix string) func(out []byte) error {
+	return func(out []byte) error {
+		if !bytes.HasPrefix(out, []byte(prefix)) {
+			return fmt.Errorf("doesn't start with %q", prefix)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,27 @@
+package valuegraph
+
+import (
+	"crypto/dsa"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/tls"
+	"reflect"
+)
+
+// keyMaterialTypes are the types of private keys and of values holding them, which aren't
+// graphed unless Config.ShowKeyMaterial is set.
+var keyMaterialTypes = map[reflect.Type]bool{
+	reflect.TypeOf(rsa.PrivateKey{}):     true,
+	reflect.TypeOf(ecdsa.PrivateKey{}):   true,
+	reflect.TypeOf(ed25519.PrivateKey{}): true,
+	reflect.TypeOf(ecdh.PrivateKey{}):    true,
+	reflect.TypeOf(dsa.PrivateKey{}):     true,
+	reflect.TypeOf(tls.Certificate{}):    true,
+}
+
+// keyMaterial reports whether v is a private key, or holds one, to be hidden.
+func (g *Graph) keyMaterial(v reflect.Value) bool {
+	return !g.cfg.ShowKeyMaterial && v.IsValid() && keyMaterialTypes[v.Type()]
+}
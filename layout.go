@@ -0,0 +1,61 @@
+package valuegraph
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/tcard/valuegraph/gographvizutil"
+)
+
+// Positions lays out the graph and returns the position of each node, keyed by node name.
+// It requires the dot command to be available in the system.
+func (g *Graph) Positions() (map[string]gographvizutil.Position, error) {
+	return gographvizutil.Positions(g.Graph, g.cfg.Layout)
+}
+
+// SeedPositions sets the initial position of every node in the graph that appears in pos,
+// typically obtained from Positions on a previous graph of the same value. Layout engines that
+// honor initial positions (Neato, Fdp) then keep the picture stable across updates instead of
+// reshuffling it; dot ignores them. Node names are only stable across graphs if the value is
+// traversed in the same order, which is not the case for maps.
+func (g *Graph) SeedPositions(pos map[string]gographvizutil.Position) {
+	g.setPositions(pos, "")
+}
+
+// Pin fixes the position of every node in the graph that appears in pins, so that Neato and Fdp
+// don't move them at all. Use it to reapply a layout curated by hand, as loaded by LoadPins.
+func (g *Graph) Pin(pins map[string]gographvizutil.Position) {
+	g.setPositions(pins, "!")
+}
+
+func (g *Graph) setPositions(pos map[string]gographvizutil.Position, suffix string) {
+	for name, p := range pos {
+		if n, ok := g.Graph.Nodes.Lookup[name]; ok {
+			n.Attrs.Add("pos", fmt.Sprintf(`"%v,%v%v"`, p.X, p.Y, suffix))
+		}
+	}
+}
+
+// SavePins writes node positions, keyed by node name, to a JSON sidecar file at path, so that
+// they can be reapplied with LoadPins and Pin on subsequent renders of the same value.
+func SavePins(path string, pins map[string]gographvizutil.Position) error {
+	b, err := json.MarshalIndent(pins, "", "\t")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, b, 0644)
+}
+
+// LoadPins reads node positions written by SavePins.
+func LoadPins(path string) (map[string]gographvizutil.Position, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var pins map[string]gographvizutil.Position
+	if err := json.Unmarshal(b, &pins); err != nil {
+		return nil, err
+	}
+	return pins, nil
+}
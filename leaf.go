@@ -0,0 +1,30 @@
+package valuegraph
+
+import "reflect"
+
+// leafLabel returns the rest of the label of v, after its type, if v is to be shown as a
+// single node instead of being walked into, and the name of the option that decided so. The
+// result is escaped for DOT.
+func (g *Graph) leafLabel(v reflect.Value, path string) (label string, handler string, ok bool) {
+	if s, ok := g.format(v); ok {
+		return escape(s), "formatter", true
+	}
+	if g.opaque(v.Type()) {
+		return `\n(opaque)`, "OpaqueTypes", true
+	}
+	if g.cfg.BitmapWidth > 0 {
+		if s, ok := g.bitmap(v); ok {
+			return escape(s), "BitmapWidth", true
+		}
+	}
+	if s, ok := g.builtin(v); ok {
+		return escape(s), "builtin", true
+	}
+	if s, ok := g.amount(v); ok {
+		return escape(s), "Amounts", true
+	}
+	if s, ok := g.stackTrace(v, path); ok {
+		return s, "StackTraces", true
+	}
+	return "", "", false
+}
@@ -0,0 +1,31 @@
+package valuegraph
+
+import "fmt"
+
+// levelFull reports whether the level at depth already has Config.LevelLimit values, counting
+// the value at path, child of parent, if not. Values left out are counted by parent, to be
+// summarized by addLevelEllipses.
+func (g *Graph) levelFull(parent string, depth int, path string) bool {
+	if g.cfg.LevelLimit <= 0 {
+		return false
+	}
+	if g.levels[depth] < g.cfg.LevelLimit {
+		g.levels[depth]++
+		return false
+	}
+	g.truncate(path, "level limit reached", "limit", g.cfg.LevelLimit, "depth", depth)
+	if g.levelCuts[parent] == 0 {
+		g.levelCutParents = append(g.levelCutParents, parent)
+	}
+	g.levelCuts[parent]++
+	return true
+}
+
+// addLevelEllipses adds a "... N more" node to each node whose children were left out
+// because of Config.LevelLimit.
+func (g *Graph) addLevelEllipses() {
+	for _, parent := range g.levelCutParents {
+		g.addLabeledChild(parent, fmt.Sprintf(`"... %v more"`, g.levelCuts[parent]))
+	}
+	g.levelCutParents = nil
+}
@@ -0,0 +1,15 @@
+package valuegraph
+
+// A Limit caps how much of a value is graphed, like Config.RangeLimit. Use NoLimit or
+// Unlimited instead of -1 for no cap; 0 is a cap too, which shows nothing of what it limits.
+//
+// Limit fields of Config used to be ints. Untyped constants, like RangeLimit: 10, still work
+// as they are, but int variables need a conversion, like Limit(n).
+type Limit int
+
+const (
+	// NoLimit means that what a Limit caps isn't capped at all.
+	NoLimit Limit = -1
+	// Unlimited is the same as NoLimit.
+	Unlimited = NoLimit
+)
@@ -0,0 +1,82 @@
+package valuegraph
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// sortKeys sorts map keys by value if they are of an ordered kind, and by their formatted
+// string otherwise, per Config.SortMapKeys.
+func (g *Graph) sortKeys(keys []reflect.Value) {
+	if !g.cfg.SortMapKeys || len(keys) == 0 {
+		return
+	}
+	var less func(a, b reflect.Value) bool
+	switch keys[0].Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		less = func(a, b reflect.Value) bool { return a.Int() < b.Int() }
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		less = func(a, b reflect.Value) bool { return a.Uint() < b.Uint() }
+	case reflect.Float32, reflect.Float64:
+		less = func(a, b reflect.Value) bool { return a.Float() < b.Float() }
+	case reflect.String:
+		less = func(a, b reflect.Value) bool { return a.String() < b.String() }
+	case reflect.Bool:
+		less = func(a, b reflect.Value) bool { return !a.Bool() && b.Bool() }
+	default:
+		strs := make(map[reflect.Value]string, len(keys))
+		for _, k := range keys {
+			strs[k] = g.keyString(k)
+		}
+		less = func(a, b reflect.Value) bool { return strs[a] < strs[b] }
+	}
+	sort.SliceStable(keys, func(i, j int) bool { return less(keys[i], keys[j]) })
+}
+
+// keyString formats the map key k, for paths and sorting.
+func (g *Graph) keyString(k reflect.Value) string {
+	if k.Kind() == reflect.String {
+		return `"` + k.String() + `"`
+	}
+	if x, ok := g.exposed(k); ok {
+		return fmt.Sprint(x.Interface())
+	}
+	return "k"
+}
+
+// selectKeys returns the keys of the map v to graph, per Config.MapKeys and
+// Config.MapKeyFilter.
+func (g *Graph) selectKeys(v reflect.Value) []reflect.Value {
+	kt := v.Type().Key()
+	var keys []reflect.Value
+	if len(g.cfg.MapKeys) > 0 {
+		listed := false
+		for _, k := range g.cfg.MapKeys {
+			kv := reflect.ValueOf(k)
+			if !kv.IsValid() || !kv.Type().AssignableTo(kt) && !(kv.Type().ConvertibleTo(kt) && kv.Kind() == kt.Kind()) {
+				continue
+			}
+			listed = true
+			if kv = kv.Convert(kt); v.MapIndex(kv).IsValid() {
+				keys = append(keys, kv)
+			}
+		}
+		if !listed {
+			keys = v.MapKeys()
+		}
+	} else {
+		keys = v.MapKeys()
+	}
+
+	if g.cfg.MapKeyFilter != nil {
+		kept := keys[:0]
+		for _, k := range keys {
+			if g.cfg.MapKeyFilter(k) {
+				kept = append(kept, k)
+			}
+		}
+		keys = kept
+	}
+	return keys
+}
@@ -0,0 +1,47 @@
+package valuegraph
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMapLimitCountsSelectedKeys(t *testing.T) {
+	m := make(map[int]bool)
+	for i := 0; i < 10; i++ {
+		m[i] = true
+	}
+	even := func(k reflect.Value) bool { return k.Int()%2 == 0 }
+	for _, tc := range []struct {
+		name     string
+		limit    Limit
+		filter   func(reflect.Value) bool
+		want     []string
+		ellipses []string
+	}{
+		{"limit only", 2, nil, []string{"map limit reached"}, []string{"... 8 more"}},
+		{"filter only", NoLimit, even, []string{"map keys not selected"}, []string{"... 5 more"}},
+		{"limit and filter", 2, even, []string{"map limit reached", "map keys not selected"}, []string{"... 3 more", "... 5 more"}},
+		{"filter at the limit", 5, even, []string{"map keys not selected"}, []string{"... 5 more"}},
+	} {
+		c := *DefaultConfig
+		c.Strict = true
+		c.MapLimit = tc.limit
+		c.MapKeyFilter = tc.filter
+		g := c.Make(m)
+
+		var got []string
+		for _, tr := range g.truncations {
+			got = append(got, strings.TrimPrefix(tr, "v: "))
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: got truncations %q, want %q", tc.name, got, tc.want)
+		}
+		dot := g.Dot()
+		for _, e := range tc.ellipses {
+			if !strings.Contains(dot, e) {
+				t.Errorf("%s: no %q in\n%s", tc.name, e, dot)
+			}
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package valuegraph
+
+import (
+	"reflect"
+)
+
+// Merge returns a new Config with the fields of c, overridden by those of overrides, so that
+// presets, configuration files and call site options can be layered, like
+// DefaultConfig.Merge(fromFile).Merge(&Config{DepthLimit: 3}). Neither c nor overrides are
+// modified.
+//
+// A field of overrides overrides c's if it isn't the zero value of its type, or if its name is
+// in overrides.Explicit, so that fields can be set to their zero value too, like
+// &Config{Explicit: []string{"UseStringers"}} to turn UseStringers off. Maps are merged
+// instead, with the entries of overrides taking precedence, unless the field is in Explicit.
+// Formatters registered in overrides are checked before those in c. The Explicit field itself
+// is taken from c.
+//
+// Merge panics if overrides.Explicit has a name that isn't a field of Config.
+func (c *Config) Merge(overrides *Config) *Config {
+	merged := *c
+
+	explicit := make(map[string]bool, len(overrides.Explicit))
+	for _, name := range overrides.Explicit {
+		if f, ok := configType.FieldByName(name); !ok || f.PkgPath != "" {
+			panic("valuegraph: Config.Explicit: no field " + name)
+		}
+		explicit[name] = true
+	}
+
+	dst, src := reflect.ValueOf(&merged).Elem(), reflect.ValueOf(overrides).Elem()
+	for i := 0; i < configType.NumField(); i++ {
+		f := configType.Field(i)
+		if f.PkgPath != "" || f.Name == "Explicit" {
+			continue
+		}
+		sv, dv := src.Field(i), dst.Field(i)
+		switch {
+		case explicit[f.Name]:
+			dv.Set(sv)
+		case sv.IsZero():
+		case f.Type.Kind() == reflect.Map && !dv.IsNil():
+			m := reflect.MakeMapWithSize(f.Type, dv.Len()+sv.Len())
+			for _, mv := range []reflect.Value{dv, sv} {
+				iter := mv.MapRange()
+				for iter.Next() {
+					m.SetMapIndex(iter.Key(), iter.Value())
+				}
+			}
+			dv.Set(m)
+		default:
+			dv.Set(sv)
+		}
+	}
+
+	for t, f := range overrides.formatters {
+		merged.RegisterFormatter(t, f)
+	}
+	if len(overrides.ifaceFormatters) > 0 {
+		merged.ifaceFormatters = append(overrides.ifaceFormatters[:len(overrides.ifaceFormatters):len(overrides.ifaceFormatters)], c.ifaceFormatters...)
+	}
+	return &merged
+}
+
+var configType = reflect.TypeOf(Config{})
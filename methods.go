@@ -0,0 +1,40 @@
+package valuegraph
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Methods beyond this many aren't listed per Config.ShowMethods.
+const maxMethods = 20
+
+// addMethods links node, of the value v, to a node listing the methods of its type, per
+// Config.ShowMethods. Values reached through pointers, which are addressable, get the methods
+// of the pointer type. The node is shared by all values of the same type.
+func (g *Graph) addMethods(node string, v reflect.Value) {
+	if !g.cfg.ShowMethods || !v.IsValid() || v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		return
+	}
+	ty := v.Type()
+	if v.CanAddr() {
+		ty = reflect.PtrTo(ty)
+	}
+	if ty.NumMethod() == 0 {
+		return
+	}
+	n, ok := g.methodNodes[ty]
+	if !ok {
+		n = g.nextNode()
+		g.methodNodes[ty] = n
+		label := escape("methods of "+ty.String()) + `\n`
+		for i := 0; i < ty.NumMethod() && i < maxMethods; i++ {
+			m := ty.Method(i)
+			label += escape(m.Name+signature(m.Type, 1)) + `\l`
+		}
+		if more := ty.NumMethod() - maxMethods; more > 0 {
+			label += escape(fmt.Sprintf("... %v more", more)) + `\l`
+		}
+		g.addNode("G", n, map[string]string{"label": `"` + label + `"`, "shape": "note"})
+	}
+	g.addEdge(node, n, map[string]string{"style": "dotted", "arrowhead": "none"})
+}
@@ -0,0 +1,64 @@
+package valuegraph
+
+import "reflect"
+
+// A NodeKey identifies a value by its address and type, so that a value is graphed once
+// however many times it is referred to, by pointers or by being held in several places. Maps,
+// slices and channels are identified by what they refer to instead, along with the length and
+// capacity of slices, so that values referring to themselves through them are graphed once
+// too.
+type NodeKey struct {
+	Addr     uintptr
+	Type     reflect.Type
+	Len, Cap int
+}
+
+// nodeKey returns the key of v, if it has an identity: that is, if it's addressable, or a
+// non-nil map, slice or channel. Values of zero size don't, since they may share an address.
+func nodeKey(v reflect.Value) (NodeKey, bool) {
+	if !v.IsValid() || v.Type().Size() == 0 {
+		return NodeKey{}, false
+	}
+	switch v.Kind() {
+	case reflect.Map, reflect.Chan:
+		if v.IsNil() {
+			return NodeKey{}, false
+		}
+		return NodeKey{Addr: v.Pointer(), Type: v.Type()}, true
+	case reflect.Slice:
+		// Empty slices with no capacity may all point to the same place.
+		if v.IsNil() || v.Cap() == 0 {
+			return NodeKey{}, false
+		}
+		return NodeKey{Addr: v.Pointer(), Type: v.Type(), Len: v.Len(), Cap: v.Cap()}, true
+	}
+	if !v.CanAddr() {
+		return NodeKey{}, false
+	}
+	return NodeKey{Addr: v.UnsafeAddr(), Type: v.Type()}, true
+}
+
+// nodeOf returns the node of v, if it was already graphed.
+func (g *Graph) nodeOf(v reflect.Value) (string, bool) {
+	k, ok := nodeKey(v)
+	if !ok {
+		return "", false
+	}
+	n, ok := g.Nodes[k]
+	return n, ok
+}
+
+// addSharedEdge links parent to the node n of v, already graphed, with an edge labeled
+// varName, instead of graphing v again.
+func (g *Graph) addSharedEdge(parent, varName, n string, v reflect.Value, edgeParams map[string]string, path string) {
+	g.debug("already graphed", path, "node", n)
+	g.addShared(n, v.Type())
+	attrs := make(map[string]string, len(edgeParams)+1)
+	for k, a := range edgeParams {
+		attrs[k] = a
+	}
+	if varName != "" {
+		attrs["label"] = quote(varName)
+	}
+	g.addEdge(parent, n, attrs)
+}
@@ -0,0 +1,66 @@
+package valuegraph
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"time"
+)
+
+// noisy returns v perturbed per Config.Noise, if it's a number, and whether it was.
+func (g *Graph) noisy(v reflect.Value) (reflect.Value, bool) {
+	if g.cfg.Noise <= 0 || v.IsZero() {
+		return v, false
+	}
+	if g.rand == nil {
+		seed := g.cfg.NoiseSeed
+		if seed == 0 {
+			seed = time.Now().UnixNano()
+		}
+		g.rand = rand.New(rand.NewSource(seed))
+	}
+
+	n := reflect.New(v.Type()).Elem()
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		// The bounds are powers of two, so they are exact as floats, unlike the largest values.
+		bits := uint(v.Type().Bits())
+		bound := math.Ldexp(1, int(bits)-1)
+		switch x := math.Round(g.perturb(float64(v.Int()))); {
+		case x >= bound:
+			n.SetInt(1<<(bits-1) - 1)
+		case x < -bound:
+			n.SetInt(-1 << (bits - 1))
+		default:
+			n.SetInt(int64(x))
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		bits := uint(v.Type().Bits())
+		switch x := math.Round(g.perturb(float64(v.Uint()))); {
+		case x >= math.Ldexp(1, int(bits)):
+			n.SetUint(math.MaxUint64 >> (64 - bits))
+		case x < 0:
+			n.SetUint(0)
+		default:
+			n.SetUint(uint64(x))
+		}
+	case reflect.Float32, reflect.Float64:
+		n.SetFloat(g.perturb(v.Float()))
+	case reflect.Complex64, reflect.Complex128:
+		c := v.Complex()
+		n.SetComplex(complex(g.perturb(real(c)), g.perturb(imag(c))))
+	default:
+		return v, false
+	}
+	return n, true
+}
+
+// perturb adds Laplace noise to x, scaled by Config.Noise relative to the magnitude of x, so
+// that the result stays plausible for its order of magnitude.
+func (g *Graph) perturb(x float64) float64 {
+	if math.IsInf(x, 0) || math.IsNaN(x) {
+		return x
+	}
+	u := g.rand.Float64() - 0.5
+	return x - g.cfg.Noise*math.Abs(x)*math.Copysign(math.Log(1-2*math.Abs(u)), u)
+}
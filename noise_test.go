@@ -0,0 +1,53 @@
+package valuegraph
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestNoiseClampsToRange(t *testing.T) {
+	for _, tc := range []struct {
+		v        interface{}
+		min, max interface{}
+	}{
+		{int64(math.MaxInt64), int64(math.MinInt64), int64(math.MaxInt64)},
+		{int64(math.MinInt64), int64(math.MinInt64), int64(math.MaxInt64)},
+		{int(math.MaxInt64), int(math.MinInt64), int(math.MaxInt64)},
+		{int8(math.MaxInt8), int8(math.MinInt8), int8(math.MaxInt8)},
+		{int8(math.MinInt8), int8(math.MinInt8), int8(math.MaxInt8)},
+		{uint64(math.MaxUint64), uint64(0), uint64(math.MaxUint64)},
+		{uint(math.MaxUint64), uint(0), uint(math.MaxUint64)},
+		{uint8(math.MaxUint8), uint8(0), uint8(math.MaxUint8)},
+	} {
+		rv := reflect.ValueOf(tc.v)
+		x := asFloat(tc.v)
+		for seed := int64(1); seed <= 50; seed++ {
+			cfg := &Config{Noise: 10, NoiseSeed: seed}
+			n, ok := cfg.newGraph().noisy(rv)
+			if !ok {
+				t.Fatalf("%T %v not perturbed", tc.v, tc.v)
+			}
+
+			// The same seed perturbs the same way, which tells whether it went out of range.
+			g := cfg.newGraph()
+			g.noisy(reflect.ValueOf(1.0))
+			g.rand.Seed(seed)
+			p := math.Round(g.perturb(x))
+			want := n.Interface()
+			switch {
+			case p >= asFloat(tc.max):
+				want = tc.max
+			case p <= asFloat(tc.min):
+				want = tc.min
+			}
+			if got := n.Interface(); got != want {
+				t.Errorf("%T %v perturbed with seed %v to %v, want %v", tc.v, tc.v, seed, got, want)
+			}
+		}
+	}
+}
+
+func asFloat(v interface{}) float64 {
+	return reflect.ValueOf(v).Convert(reflect.TypeOf(0.0)).Float()
+}
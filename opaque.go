@@ -0,0 +1,39 @@
+package valuegraph
+
+import (
+	"reflect"
+	"strings"
+)
+
+// opaque reports whether values of type t are graphed as a single node, per
+// Config.OpaqueTypes and Config.OpaqueTypeNames.
+func (g *Graph) opaque(t reflect.Type) bool {
+	if g.opaqueTypes == nil {
+		g.opaqueTypes = make(map[reflect.Type]bool, len(g.cfg.OpaqueTypes))
+		for _, ot := range g.cfg.OpaqueTypes {
+			g.opaqueTypes[ot] = true
+		}
+		g.opaqueNames = make(map[string]bool, len(g.cfg.OpaqueTypeNames))
+		for _, name := range g.cfg.OpaqueTypeNames {
+			g.opaqueNames[name] = true
+		}
+	}
+	if len(g.opaqueTypes) == 0 && len(g.opaqueNames) == 0 {
+		return false
+	}
+	return g.opaqueTypes[t] || g.opaqueNames[t.String()] || g.opaqueNames[qualifiedName(t)]
+}
+
+// qualifiedName returns the name of t with the full import path of its package, like
+// "*database/sql.DB", or "" if t isn't a, maybe pointer to, defined type.
+func qualifiedName(t reflect.Type) string {
+	stars := 0
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+		stars++
+	}
+	if t.Name() == "" || t.PkgPath() == "" {
+		return ""
+	}
+	return strings.Repeat("*", stars) + t.PkgPath() + "." + t.Name()
+}
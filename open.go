@@ -0,0 +1,266 @@
+package valuegraph
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/tcard/valuegraph/gographvizutil"
+)
+
+// OpenSVG is a convenience function for opening a graph visualization of the value in the system SVG visualizer.
+// It is intended for debugging.
+// Uses DefaultConfig.
+func OpenSVG(v interface{}) error {
+	return DefaultConfig.OpenSVG(v)
+}
+
+// OpenSVG is a convenience method for opening a graph visualization of the value in the system SVG visualizer.
+// It is intended for debugging.
+func (c *Config) OpenSVG(v interface{}) error {
+	_, err := c.Open(v, gographvizutil.SVG)
+	return err
+}
+
+// OpenPNG is like OpenSVG, but opens a PNG image.
+// Uses DefaultConfig.
+func OpenPNG(v interface{}) error {
+	return DefaultConfig.OpenPNG(v)
+}
+
+// OpenPNG is like OpenSVG, but opens a PNG image.
+func (c *Config) OpenPNG(v interface{}) error {
+	_, err := c.Open(v, gographvizutil.PNG)
+	return err
+}
+
+// OpenPDF is like OpenSVG, but opens a PDF document.
+// Uses DefaultConfig.
+func OpenPDF(v interface{}) error {
+	return DefaultConfig.OpenPDF(v)
+}
+
+// OpenPDF is like OpenSVG, but opens a PDF document.
+func (c *Config) OpenPDF(v interface{}) error {
+	_, err := c.Open(v, gographvizutil.PDF)
+	return err
+}
+
+// OpenDot is like OpenSVG, but opens the graph in dot format with the system's default
+// application for .dot files, typically a text editor. It doesn't need the dot command.
+// Uses DefaultConfig.
+func OpenDot(v interface{}) error {
+	return DefaultConfig.OpenDot(v)
+}
+
+// OpenDot is like OpenSVG, but opens the graph in dot format with the system's default
+// application for .dot files, typically a text editor. It doesn't need the dot command.
+func (c *Config) OpenDot(v interface{}) error {
+	_, err := c.Open(v, "dot")
+	return err
+}
+
+// Open writes a graph visualization of the value in the given format to a temp file and opens
+// it with c.Viewer or, if empty, with the first of the commands in the BROWSER environment
+// variable, a known browser or the system's default application that works. The "dot" format
+// writes the graph in dot format without running the dot command, and is opened with c.Viewer
+// or the system's default application.
+// It returns the path to the written file, also on failure to open it if the file was written.
+func (c *Config) Open(v interface{}, f gographvizutil.Format) (string, error) {
+	g := c.Make(v)
+	var s string
+	var cmds []string
+	if f == "dot" {
+		if err := g.Err(); err != nil {
+			return "", err
+		}
+		s = g.Dot()
+		cmds = systemOpeners()
+	} else {
+		var err error
+		if s, err = g.render(f); err != nil {
+			return "", err
+		}
+		cmds = browsers()
+	}
+	if c.Viewer != "" {
+		cmds = []string{c.Viewer}
+	}
+
+	file, err := c.createTemp(string(f))
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := file.Write([]byte(s)); err != nil {
+		file.Close()
+		return "", err
+	}
+	if err := file.Close(); err != nil {
+		return "", err
+	}
+
+	// From go tool pprof.
+	for _, cmd := range cmds {
+		args := splitCommand(cmd)
+		if len(args) == 0 {
+			continue
+		}
+		viewer := exec.Command(args[0], append(args[1:], file.Name())...)
+		viewer.Stderr = os.Stderr
+		if err = viewer.Start(); err == nil {
+			return file.Name(), nil
+		}
+	}
+
+	return file.Name(), errors.New("no command to open " + strings.ToUpper(string(f)) + " found; temp file is at " + file.Name())
+}
+
+func (c *Config) createTemp(ext string) (*os.File, error) {
+	dir := c.TempDir
+	if dir == "" {
+		dir = os.TempDir()
+	}
+	if c.TempTTL > 0 {
+		removeExpired(dir, c.TempTTL)
+	}
+	f, err := ioutil.TempFile(dir, c.tempPattern()+"."+ext)
+	if err != nil {
+		return nil, err
+	}
+	if c.TempTTL > 0 {
+		markWritten(f.Name())
+	}
+	tempFiles.Lock()
+	tempFiles.paths = append(tempFiles.paths, f.Name())
+	tempFiles.Unlock()
+	return f, nil
+}
+
+// tempPattern returns c.TempPattern, or its default, with a "*" so that the random string
+// TempFile adds never goes after the extension.
+func (c *Config) tempPattern() string {
+	pattern := c.TempPattern
+	if pattern == "" {
+		pattern = "valuegraph-*"
+	}
+	if !strings.Contains(pattern, "*") {
+		pattern += "*"
+	}
+	return pattern
+}
+
+// Files written by Open with a TempTTL have an empty marker file of the same name in this
+// subdirectory of their directory, so that only they are ever removed as expired.
+const markerDir = ".valuegraph"
+
+// markWritten leaves a marker for the file at path. Without it, the file is just never
+// removed as expired.
+func markWritten(path string) {
+	dir := filepath.Join(filepath.Dir(path), markerDir)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return
+	}
+	if m, err := os.Create(filepath.Join(dir, filepath.Base(path))); err == nil {
+		m.Close()
+	}
+}
+
+// removeExpired removes the files in dir marked as written by Open more than ttl ago, and
+// their markers.
+func removeExpired(dir string, ttl time.Duration) {
+	markers, _ := ioutil.ReadDir(filepath.Join(dir, markerDir))
+	for _, m := range markers {
+		if m.IsDir() || time.Since(m.ModTime()) <= ttl {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, m.Name())); err == nil || os.IsNotExist(err) {
+			os.Remove(filepath.Join(dir, markerDir, m.Name()))
+		}
+	}
+}
+
+// Files written by Open in this process, for CleanupTempFiles.
+var tempFiles struct {
+	sync.Mutex
+	paths []string
+}
+
+// CleanupTempFiles removes the files written so far by OpenSVG and friends in this process.
+// Defer it in main to leave no files behind when the process exits. Viewers that haven't
+// read their file yet will fail to open it.
+func CleanupTempFiles() error {
+	tempFiles.Lock()
+	defer tempFiles.Unlock()
+	var firstErr error
+	for _, p := range tempFiles.paths {
+		if err := os.Remove(p); err != nil && !os.IsNotExist(err) && firstErr == nil {
+			firstErr = err
+		}
+		os.Remove(filepath.Join(filepath.Dir(p), markerDir, filepath.Base(p)))
+	}
+	tempFiles.paths = nil
+	return firstErr
+}
+
+// splitCommand splits cmd into arguments separated by spaces. Spaces inside double or single
+// quotes don't separate, and the quotes are dropped, like in "'/Program Files/viewer' --new".
+// Backslashes are kept as they are, since they separate Windows paths.
+func splitCommand(cmd string) []string {
+	var args []string
+	var arg strings.Builder
+	inArg := false
+	var quote rune
+	for _, r := range cmd {
+		switch {
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			arg.WriteRune(r)
+		case r == '"' || r == '\'':
+			quote, inArg = r, true
+		case unicode.IsSpace(r):
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		default:
+			arg.WriteRune(r)
+			inArg = true
+		}
+	}
+	if inArg {
+		args = append(args, arg.String())
+	}
+	return args
+}
+
+func browsers() []string {
+	var cmds []string
+	// BROWSER is a list of commands separated by the OS path list separator, by convention.
+	if env := os.Getenv("BROWSER"); env != "" {
+		cmds = append(cmds, filepath.SplitList(env)...)
+	}
+	// From go tool pprof.
+	cmds = append(cmds, "chrome", "google-chrome", "firefox")
+	return append(cmds, systemOpeners()...)
+}
+
+func systemOpeners() []string {
+	switch runtime.GOOS {
+	case "darwin":
+		return []string{"/usr/bin/open"}
+	case "windows":
+		return []string{"cmd /c start"}
+	default:
+		return []string{"xdg-open"}
+	}
+}
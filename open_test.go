@@ -0,0 +1,93 @@
+package valuegraph
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCreateTemp(t *testing.T) {
+	for _, tc := range []struct {
+		pattern      string
+		prefix, glob string
+	}{
+		{"", "valuegraph-", "valuegraph-*.svg"},
+		{"graph-*", "graph-", "graph-*.svg"},
+		{"graph", "graph", "graph*.svg"},
+		{"*-graph", "", "*-graph.svg"},
+	} {
+		dir := t.TempDir()
+		f, err := (&Config{TempDir: dir, TempPattern: tc.pattern}).createTemp("svg")
+		if err != nil {
+			t.Fatalf("pattern %q: %v", tc.pattern, err)
+		}
+		f.Close()
+
+		name := filepath.Base(f.Name())
+		if !strings.HasPrefix(name, tc.prefix) || !strings.HasSuffix(name, ".svg") {
+			t.Errorf("pattern %q: got file %q", tc.pattern, name)
+		}
+		if ok, _ := filepath.Match(tc.glob, name); !ok {
+			t.Errorf("pattern %q: file %q doesn't match %q", tc.pattern, name, tc.glob)
+		}
+	}
+}
+
+func TestTempTTLRemovesOnlyWrittenFiles(t *testing.T) {
+	dir := t.TempDir()
+	c := &Config{TempDir: dir, TempPattern: "report-*", TempTTL: time.Hour}
+	old := time.Now().Add(-2 * time.Hour)
+
+	other := filepath.Join(dir, "report-final.pdf")
+	if err := os.WriteFile(other, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	os.Chtimes(other, old, old)
+	f, err := c.createTemp("svg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	written := f.Name()
+	os.Chtimes(filepath.Join(dir, markerDir, filepath.Base(written)), old, old)
+
+	f, err = c.createTemp("svg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	if _, err := os.Stat(written); !os.IsNotExist(err) {
+		t.Errorf("expired file %v not removed", written)
+	}
+	if _, err := os.Stat(other); err != nil {
+		t.Errorf("file not written by Open removed: %v", err)
+	}
+	if _, err := os.Stat(f.Name()); err != nil {
+		t.Errorf("new file removed: %v", err)
+	}
+}
+
+func TestSplitCommand(t *testing.T) {
+	for _, tc := range []struct {
+		cmd  string
+		want []string
+	}{
+		{"", nil},
+		{"   ", nil},
+		{"xdg-open", []string{"xdg-open"}},
+		{"cmd /c start", []string{"cmd", "/c", "start"}},
+		{"  open   -a  Preview ", []string{"open", "-a", "Preview"}},
+		{`"/Applications/Preview App/viewer" --new`, []string{"/Applications/Preview App/viewer", "--new"}},
+		{`'C:\Program Files\Viewer\viewer.exe' /new`, []string{`C:\Program Files\Viewer\viewer.exe`, "/new"}},
+		{`viewer --title "it's" ""`, []string{"viewer", "--title", "it's", ""}},
+		{`pre"fix and"post`, []string{"prefix andpost"}},
+		{`"unterminated arg`, []string{"unterminated arg"}},
+	} {
+		if got := splitCommand(tc.cmd); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("splitCommand(%q) = %q, want %q", tc.cmd, got, tc.want)
+		}
+	}
+}
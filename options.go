@@ -0,0 +1,73 @@
+package valuegraph
+
+import "reflect"
+
+// An Option changes a Config, for one-off calls to Make like
+// Make(v, WithDepth(3), WithTheme(Dark)), without setting up a Config.
+type Option func(c *Config)
+
+// With returns a copy of c with opts applied, in order. c isn't modified.
+func (c *Config) With(opts ...Option) *Config {
+	with := *c
+	for _, opt := range opts {
+		opt(&with)
+	}
+	return &with
+}
+
+// WithDepth sets Config.DepthLimit.
+func WithDepth(n Limit) Option {
+	return func(c *Config) { c.DepthLimit = n }
+}
+
+// WithRangeLimit sets Config.RangeLimit.
+func WithRangeLimit(n Limit) Option {
+	return func(c *Config) { c.RangeLimit = n }
+}
+
+// WithMapLimit sets Config.MapLimit.
+func WithMapLimit(n Limit) Option {
+	return func(c *Config) { c.MapLimit = n }
+}
+
+// WithStringLimit sets Config.StringLimit.
+func WithStringLimit(n Limit) Option {
+	return func(c *Config) { c.StringLimit = n }
+}
+
+// WithNodeLimit sets Config.NodeLimit.
+func WithNodeLimit(n int) Option {
+	return func(c *Config) { c.NodeLimit = n }
+}
+
+// WithOrder sets Config.Order.
+func WithOrder(o Order) Option {
+	return func(c *Config) { c.Order = o }
+}
+
+// WithUnexported sets Config.ReadUnexported.
+func WithUnexported() Option {
+	return func(c *Config) { c.ReadUnexported = true }
+}
+
+// WithTheme sets the Theme of Config.Style.
+func WithTheme(t *Theme) Option {
+	return func(c *Config) { c.Style.Theme = t }
+}
+
+// WithFormatter registers f for values of type t, as RegisterFormatter does.
+func WithFormatter(t reflect.Type, f Formatter) Option {
+	return func(c *Config) { c.RegisterFormatter(t, f) }
+}
+
+// WithInterfaceFormatter registers f for values of types implementing iface, as
+// RegisterInterfaceFormatter does.
+func WithInterfaceFormatter(iface reflect.Type, f Formatter) Option {
+	return func(c *Config) { c.RegisterInterfaceFormatter(iface, f) }
+}
+
+// WithConfig merges overrides into the Config, as Merge does, for options that have no With
+// function.
+func WithConfig(overrides *Config) Option {
+	return func(c *Config) { *c = *c.Merge(overrides) }
+}
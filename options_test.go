@@ -0,0 +1,65 @@
+package valuegraph
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type optionsTree struct {
+	Name string
+	Kids []*optionsTree
+}
+
+func TestMakeWithOptions(t *testing.T) {
+	tree := &optionsTree{"a", []*optionsTree{{"b", []*optionsTree{{"c", nil}}}}}
+	secondFormatter := func(v reflect.Value) string { return fmt.Sprintf("%vs", v.Interface().(time.Duration).Seconds()) }
+	for _, tc := range []struct {
+		name          string
+		v             interface{}
+		opts          []Option
+		want, notWant string
+	}{
+		{"no options", tree, nil, `v.Kids[0].Kids[0].Name`, ""},
+		{"depth", tree, []Option{WithDepth(2)}, `depth limit 2 reached`, `v.Kids[0].Kids[0].Name`},
+		{"range limit", []int{1, 2, 3, 4}, []Option{WithRangeLimit(2)}, `... 2 more`, ""},
+		{"formatter", time.Second, []Option{WithFormatter(reflect.TypeOf(time.Duration(0)), secondFormatter)}, `1s`, ""},
+		{"config", []int{1, 2, 3, 4}, []Option{WithConfig(&Config{RangeLimit: 1, Explicit: []string{"RangeLimit"}})}, `... 3 more`, ""},
+		{"in order", []int{1, 2, 3, 4}, []Option{WithRangeLimit(1), WithRangeLimit(3)}, `... 1 more`, ""},
+	} {
+		dot := Make(tc.v, tc.opts...).Dot()
+		if !strings.Contains(dot, tc.want) {
+			t.Errorf("%s: no %q in\n%s", tc.name, tc.want, dot)
+		}
+		if tc.notWant != "" && strings.Contains(dot, tc.notWant) {
+			t.Errorf("%s: %q in\n%s", tc.name, tc.notWant, dot)
+		}
+	}
+}
+
+func TestWithDoesNotModifyReceiver(t *testing.T) {
+	c := &Config{DepthLimit: 5, RangeLimit: 7}
+	c.RegisterInterfaceFormatter(reflect.TypeOf((*fmt.Stringer)(nil)).Elem(), func(reflect.Value) string { return "" })
+	before := *c
+
+	with := c.With(
+		WithDepth(1),
+		WithTheme(Dark),
+		WithFormatter(reflect.TypeOf(0), func(reflect.Value) string { return "" }),
+		WithInterfaceFormatter(reflect.TypeOf((*error)(nil)).Elem(), func(reflect.Value) string { return "" }),
+		WithConfig(&Config{RangeLimit: 2, Explicit: []string{"RangeLimit"}}),
+	)
+	if with == c {
+		t.Fatal("With returned its receiver")
+	}
+	if with.DepthLimit != 1 || with.RangeLimit != 2 || with.Style.Theme != Dark ||
+		len(with.formatters) != 1 || len(with.ifaceFormatters) != 2 {
+		t.Errorf("options not applied: %+v", with)
+	}
+	if c.DepthLimit != before.DepthLimit || c.RangeLimit != before.RangeLimit || c.Style.Theme != before.Style.Theme ||
+		len(c.formatters) != 0 || len(c.ifaceFormatters) != 1 {
+		t.Errorf("receiver modified: %+v", c)
+	}
+}
@@ -0,0 +1,90 @@
+package valuegraph
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// A typePlan holds what traversal needs to know about a type, computed once per type and
+// process so that graphing the same types repeatedly doesn't query reflection metadata again.
+type typePlan struct {
+	name   string
+	fields []fieldPlan
+	// Indexes of the fields of structs pointing to their own type, like the next pointer of
+	// linked list elements.
+	links []int
+}
+
+// A fieldPlan describes a struct field.
+type fieldPlan struct {
+	index    int
+	name     string
+	exported bool
+	embedded bool
+	tag      reflect.StructTag
+	// From the valuegraph struct tag.
+	label    string
+	skip     bool
+	redact   bool
+	collapse bool
+}
+
+var typePlans sync.Map // reflect.Type -> *typePlan
+
+func planFor(ty reflect.Type) *typePlan {
+	if p, ok := typePlans.Load(ty); ok {
+		return p.(*typePlan)
+	}
+
+	p := &typePlan{name: ty.String()}
+	if ty.Kind() == reflect.Struct {
+		nf := ty.NumField()
+		p.fields = make([]fieldPlan, nf)
+		for i := 0; i < nf; i++ {
+			f := ty.Field(i)
+			p.fields[i] = fieldPlan{
+				index:    i,
+				name:     f.Name,
+				exported: f.PkgPath == "",
+				embedded: f.Anonymous,
+				tag:      f.Tag,
+				label:    f.Name,
+			}
+			p.fields[i].parseTag(f.Tag.Get("valuegraph"))
+			if f.Type.Kind() == reflect.Ptr && f.Type.Elem() == ty && !p.fields[i].skip {
+				p.links = append(p.links, i)
+			}
+		}
+	}
+
+	actual, _ := typePlans.LoadOrStore(ty, p)
+	return actual.(*typePlan)
+}
+
+// parseTag applies a valuegraph struct tag to f. The tag is a comma-separated list whose first
+// element, if not empty, is the name to show for the field, or "-" to skip it, whatever
+// follows, and the rest are options: "redact" to hide its value, "collapse" to show it without
+// walking into it.
+func (f *fieldPlan) parseTag(tag string) {
+	if tag == "" {
+		return
+	}
+	parts := strings.Split(tag, ",")
+	switch parts[0] {
+	case "-":
+		f.skip = true
+		return
+	case "":
+	default:
+		f.label = parts[0]
+	}
+	for _, opt := range parts[1:] {
+		switch opt {
+		case "redact":
+			f.redact = true
+		case "collapse":
+			f.collapse = true
+		}
+	}
+}
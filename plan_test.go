@@ -0,0 +1,25 @@
+package valuegraph
+
+import "testing"
+
+func TestParseTag(t *testing.T) {
+	for _, tc := range []struct {
+		tag  string
+		want fieldPlan
+	}{
+		{"", fieldPlan{label: "Field"}},
+		{"-", fieldPlan{label: "Field", skip: true}},
+		{"-,redact", fieldPlan{label: "Field", skip: true}},
+		{"token", fieldPlan{label: "token"}},
+		{"token,redact", fieldPlan{label: "token", redact: true}},
+		{",collapse", fieldPlan{label: "Field", collapse: true}},
+		{"x,redact,collapse", fieldPlan{label: "x", redact: true, collapse: true}},
+		{"x,unknown", fieldPlan{label: "x"}},
+	} {
+		got := fieldPlan{label: "Field"}
+		got.parseTag(tc.tag)
+		if got != tc.want {
+			t.Errorf("parseTag(%q) = %+v, want %+v", tc.tag, got, tc.want)
+		}
+	}
+}
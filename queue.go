@@ -0,0 +1,39 @@
+package valuegraph
+
+import "reflect"
+
+// A pending value, already assigned a node, waiting to be visited when the traversal isn't
+// DepthFirst.
+type pending struct {
+	node, parent, varName string
+	v                     reflect.Value
+	depth, limit          int
+	edgeParams            map[string]string
+	path                  string
+	priority              float64
+	seq                   int
+}
+
+// A pendingQueue is a container/heap of pending values, ordered by decreasing priority and
+// then by order of arrival.
+type pendingQueue []pending
+
+func (q pendingQueue) Len() int { return len(q) }
+
+func (q pendingQueue) Less(i, j int) bool {
+	if q[i].priority != q[j].priority {
+		return q[i].priority > q[j].priority
+	}
+	return q[i].seq < q[j].seq
+}
+
+func (q pendingQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *pendingQueue) Push(x interface{}) { *q = append(*q, x.(pending)) }
+
+func (q *pendingQueue) Pop() interface{} {
+	old := *q
+	p := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return p
+}
@@ -0,0 +1,24 @@
+package valuegraph
+
+import "reflect"
+
+// Sensitive is implemented by types whose values are never to be shown in graphs, like
+// credentials. Their nodes are labeled «redacted» instead; see also Config.Redact.
+type Sensitive interface {
+	Sensitive()
+}
+
+var sensitiveType = reflect.TypeOf((*Sensitive)(nil)).Elem()
+
+// redacted reports whether the value v at path is to be hidden, per Config.Redact and
+// Sensitive.
+func (g *Graph) redacted(path string, v reflect.Value) bool {
+	if v.IsValid() && (v.Type().Implements(sensitiveType) || reflect.PtrTo(v.Type()).Implements(sensitiveType)) {
+		return true
+	}
+	if len(g.redact) == 0 {
+		return false
+	}
+	rel := g.relPath(path)
+	return matchAny(g.redact, lastName(rel), rel)
+}
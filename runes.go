@@ -0,0 +1,32 @@
+package valuegraph
+
+import (
+	"reflect"
+	"strconv"
+	"unicode"
+)
+
+// runeSuffix returns the character that the int32 or uint8 x stands for, quoted and preceded
+// by a space, if Config.ShowRunes is set and it's printable, or ASCII for uint8. The result is
+// escaped for DOT.
+func (g *Graph) runeSuffix(x reflect.Value) string {
+	if !g.cfg.ShowRunes {
+		return ""
+	}
+	var r rune
+	switch x.Kind() {
+	case reflect.Int32:
+		r = rune(x.Int())
+	case reflect.Uint8:
+		if x.Uint() >= 0x80 {
+			return ""
+		}
+		r = rune(x.Uint())
+	default:
+		return ""
+	}
+	if !unicode.IsPrint(r) {
+		return ""
+	}
+	return " " + escape(strconv.QuoteRune(r))
+}
@@ -0,0 +1,118 @@
+package valuegraph
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// addRuns is like addElements, for Config.FoldRuns: each run of consecutive equal elements is
+// graphed as a single node, labeled with the range of indices it covers, with an edge labeled
+// with its length, and counts as one element for Config.RangeLimit. Only the elements in the
+// runs shown are compared.
+func (g *Graph) addRuns(node string, v reflect.Value, depth int, limit int, path string, rangeLimit int) {
+	l := v.Len()
+	head, tail := rangeLimit, 0
+	if g.cfg.Sampling == SampleHeadTail {
+		head = (rangeLimit + 1) / 2
+		tail = rangeLimit - head
+	}
+
+	var runs [][2]int
+	i := 0
+	for r := 0; r < head && i < l; r++ {
+		j := g.runFrom(v, i, 1, l)
+		runs = append(runs, [2]int{i, j})
+		i = j
+	}
+	headRuns := len(runs)
+	var tailRuns [][2]int
+	end := l
+	for r := 0; r < tail && end > i; r++ {
+		j := g.runFrom(v, end-1, -1, i-1) + 1
+		tailRuns = append(tailRuns, [2]int{j, end})
+		end = j
+	}
+	for k := len(tailRuns) - 1; k >= 0; k-- {
+		runs = append(runs, tailRuns[k])
+	}
+
+	omitted := end - i
+	at := -1
+	if omitted > 0 {
+		switch g.cfg.Ellipsis {
+		case EllipsisBefore:
+			at = 0
+		case EllipsisAfter:
+			at = len(runs)
+		default:
+			at = headRuns
+		}
+	}
+	for k, run := range runs {
+		if k == at {
+			g.addEllipsis(node, omitted, path, "range limit reached", rangeLimit)
+		}
+		g.addRun(node, v, run[0], run[1], depth, limit, path)
+	}
+	if at == len(runs) {
+		g.addEllipsis(node, omitted, path, "range limit reached", rangeLimit)
+	}
+}
+
+// addRun adds the elements from i to j of v, all equal, as a child of node.
+func (g *Graph) addRun(node string, v reflect.Value, i, j int, depth int, limit int, path string) {
+	idx := "[" + strconv.Itoa(i) + "]"
+	if j-i == 1 {
+		g.addValue(node, idx, v.Index(i), depth+1, limit, nil, path+idx)
+		return
+	}
+	g.debug("run of equal elements folded", path+idx, "length", j-i)
+	g.addValue(node, fmt.Sprintf("[%v:%v]", i, j), v.Index(i), depth+1, limit, map[string]string{
+		"label": quote(fmt.Sprintf("×%v", j-i)),
+	}, path+idx)
+}
+
+// Runs are cut after this many elements, so that graphing long runs of equal elements
+// doesn't compare all of them when only a few nodes are shown.
+const maxRunLength = 1 << 16
+
+// runFrom returns the index past the run of elements of v equal to the one at i, stepping by
+// step, which is 1 or -1, up to stop. Runs are at most maxRunLength elements long.
+func (g *Graph) runFrom(v reflect.Value, i, step, stop int) int {
+	j := i + step
+	x, ok := g.comparable(v.Index(i))
+	if !ok {
+		return j
+	}
+	for n := 1; j != stop && n < maxRunLength; n++ {
+		if y, ok := g.comparable(v.Index(j)); !ok || !equal(x, y) {
+			break
+		}
+		j += step
+	}
+	return j
+}
+
+// comparable returns v as an interface that can be compared with == to tell whether values
+// would be graphed the same. Pointers are equal only if they point to the same value.
+func (g *Graph) comparable(v reflect.Value) (interface{}, bool) {
+	if !v.Type().Comparable() {
+		return nil, false
+	}
+	x, ok := g.exposed(v)
+	if !ok {
+		return nil, false
+	}
+	return x.Interface(), true
+}
+
+func equal(x, y interface{}) (same bool) {
+	// Interfaces holding values of types that aren't comparable panic.
+	defer func() {
+		if recover() != nil {
+			same = false
+		}
+	}()
+	return x == y
+}
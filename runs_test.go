@@ -0,0 +1,57 @@
+package valuegraph
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFoldRuns(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		v        interface{}
+		sampling Sampling
+		limit    Limit
+		want     []string
+	}{{
+		name:  "runs",
+		v:     []int{0, 0, 0, 1, 1, 2},
+		limit: 5,
+		want:  []string{`label="[0:3]\nint: 0"`, `label="×3"`, `label="[3:5]\nint: 1"`, `label="[5]\nint: 2"`},
+	}, {
+		name:  "head",
+		v:     []string{"a", "a", "b", "c"},
+		limit: 1,
+		want:  []string{`label="[0:2]\nstring len: 1\na"`, `label="... 2 more"`},
+	}, {
+		name:     "head and tail",
+		v:        []int{1, 2, 2, 3, 4, 4, 4},
+		sampling: SampleHeadTail,
+		limit:    2,
+		want:     []string{`label="[0]\nint: 1"`, `label="... 3 more"`, `label="[4:7]\nint: 4"`},
+	}, {
+		name:  "long run",
+		v:     make([]byte, 3*maxRunLength),
+		limit: 1,
+		want:  []string{`label="[0:65536]\nuint8: 0"`, `label="... 131072 more"`},
+	}, {
+		name:  "not comparable",
+		v:     [][]int{nil, nil},
+		limit: 5,
+		want:  []string{`label="[0]\n[]int\nslice: <nil>"`, `label="[1]\n[]int\nslice: <nil>"`},
+	}} {
+		c := *DefaultConfig
+		c.FoldRuns, c.Sampling, c.RangeLimit, c.HexdumpLimit = true, tc.sampling, tc.limit, 0
+		dot := c.Make(tc.v).Dot()
+		for _, w := range tc.want {
+			if !strings.Contains(dot, w) {
+				t.Errorf("%s: no %s in\n%s", tc.name, w, dot)
+			}
+		}
+	}
+}
+
+func TestFoldRunsOffByDefault(t *testing.T) {
+	if dot := Make([]int{0, 0, 0}).Dot(); strings.Contains(dot, "×") {
+		t.Errorf("runs folded by default:\n%s", dot)
+	}
+}
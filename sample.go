@@ -0,0 +1,108 @@
+package valuegraph
+
+import (
+	"math/rand"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// A Sampling is the way the elements of slices and arrays longer than Config.RangeLimit are
+// chosen.
+type Sampling int
+
+const (
+	// SampleHead shows the first elements.
+	SampleHead Sampling = iota
+	// SampleHeadTail shows the first and last elements, half and half, with the ones left out
+	// in between.
+	SampleHeadTail
+	// SampleRandom shows elements chosen at random, in order, with their indices.
+	SampleRandom
+)
+
+// An EllipsisPosition is where the "... N more" node summarizing the elements of a slice or
+// array left out by Config.RangeLimit goes among the elements shown.
+type EllipsisPosition int
+
+const (
+	// EllipsisInPlace puts it where the elements left out are: after the first elements, or
+	// between the first and last ones with SampleHeadTail. SampleRandom puts it after all.
+	EllipsisInPlace EllipsisPosition = iota
+	// EllipsisBefore puts it before all the elements shown.
+	EllipsisBefore
+	// EllipsisAfter puts it after all the elements shown.
+	EllipsisAfter
+)
+
+// addElements adds the elements of the slice or array v as children of node, sampled per
+// Config.RangeLimit and Config.Sampling.
+func (g *Graph) addElements(node string, v reflect.Value, depth int, limit int, path string) {
+	l := v.Len()
+	rangeLimit := g.rangeLimit()
+	if rangeLimit < 0 || rangeLimit >= l {
+		rangeLimit = l
+	}
+	if g.cfg.FoldRuns && g.cfg.Sampling != SampleRandom {
+		g.addRuns(node, v, depth, limit, path, rangeLimit)
+		return
+	}
+
+	var indices []int
+	switch g.cfg.Sampling {
+	case SampleHeadTail:
+		head := (rangeLimit + 1) / 2
+		for i := 0; i < head; i++ {
+			indices = append(indices, i)
+		}
+		for i := l - (rangeLimit - head); i < l; i++ {
+			indices = append(indices, i)
+		}
+	case SampleRandom:
+		indices = g.sampleRand().Perm(l)[:rangeLimit]
+		sort.Ints(indices)
+	default:
+		for i := 0; i < rangeLimit; i++ {
+			indices = append(indices, i)
+		}
+	}
+
+	// Elements left out are summarized where they are, except for SampleRandom, which
+	// summarizes them all in one place.
+	omitted := l - len(indices)
+	inPlace := g.cfg.Ellipsis == EllipsisInPlace && g.cfg.Sampling != SampleRandom
+	if omitted > 0 && g.cfg.Ellipsis == EllipsisBefore {
+		g.addEllipsis(node, omitted, path, "range limit reached", rangeLimit)
+	}
+	next := 0
+	for _, i := range indices {
+		if i > next && inPlace {
+			g.addEllipsis(node, i-next, path, "range limit reached", rangeLimit)
+		}
+		idx := "[" + strconv.Itoa(i) + "]"
+		g.addValue(node, idx, v.Index(i), depth+1, limit, nil, path+idx)
+		next = i + 1
+	}
+	switch {
+	case omitted == 0 || g.cfg.Ellipsis == EllipsisBefore:
+	case inPlace:
+		if next < l {
+			g.addEllipsis(node, l-next, path, "range limit reached", rangeLimit)
+		}
+	default:
+		g.addEllipsis(node, omitted, path, "range limit reached", rangeLimit)
+	}
+}
+
+// sampleRand returns the source of SampleRandom, created on first use.
+func (g *Graph) sampleRand() *rand.Rand {
+	if g.sampleSource == nil {
+		seed := g.cfg.SampleSeed
+		if seed == 0 {
+			seed = time.Now().UnixNano()
+		}
+		g.sampleSource = rand.New(rand.NewSource(seed))
+	}
+	return g.sampleSource
+}
@@ -0,0 +1,30 @@
+package valuegraph
+
+import (
+	"reflect"
+	"strconv"
+)
+
+// appendScalar returns label followed by ": " and the text of the boolean or number x, as
+// fmt.Sprint would print it, with a single allocation. It returns false for complex numbers
+// and for types with methods, which fmt.Sprint may call.
+func appendScalar(label string, x reflect.Value) (string, bool) {
+	if x.Type().NumMethod() > 0 {
+		return "", false
+	}
+	b := make([]byte, 0, len(label)+26)
+	b = append(append(b, label...), ": "...)
+	switch x.Kind() {
+	case reflect.Bool:
+		b = strconv.AppendBool(b, x.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		b = strconv.AppendInt(b, x.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		b = strconv.AppendUint(b, x.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		b = strconv.AppendFloat(b, x.Float(), 'g', -1, x.Type().Bits())
+	default:
+		return "", false
+	}
+	return string(b), true
+}
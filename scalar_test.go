@@ -0,0 +1,32 @@
+package valuegraph
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestAppendScalarLikeSprint(t *testing.T) {
+	type named int
+	for _, v := range []interface{}{
+		true, false, 0, -1, int8(math.MinInt8), int64(math.MaxInt64), uint64(math.MaxUint64),
+		uintptr(0xdead), named(7), 0.1, 1e21, 1e-7, float32(0.1), math.Inf(1), math.NaN(),
+		-0.0, float32(math.MaxFloat32),
+	} {
+		got, ok := appendScalar("x", reflect.ValueOf(v))
+		if !ok {
+			t.Errorf("%T %v: not appended", v, v)
+			continue
+		}
+		if want := "x: " + fmt.Sprint(v); got != want {
+			t.Errorf("%T: got %q, want %q", v, got, want)
+		}
+	}
+	for _, v := range []interface{}{time.Second, complex(1, 2), "s"} {
+		if _, ok := appendScalar("x", reflect.ValueOf(v)); ok {
+			t.Errorf("%T %v: appended, want fmt.Sprint", v, v)
+		}
+	}
+}
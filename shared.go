@@ -0,0 +1,62 @@
+package valuegraph
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"text/tabwriter"
+)
+
+// A SharedValue is a value referred to more than once in a graph, and graphed once: the
+// target of several pointers, or a map or slice held in several places.
+type SharedValue struct {
+	// Path and node of the value where it was first found.
+	Path string
+	Node string
+	Type reflect.Type
+	// How many times the value is referred to, including the first.
+	References int
+}
+
+// addShared counts another reference to the value of type t graphed as node.
+func (g *Graph) addShared(node string, t reflect.Type) {
+	if g.stream != nil {
+		return
+	}
+	if s, ok := g.shared[node]; ok {
+		s.References++
+		return
+	}
+	g.shared[node] = &SharedValue{Path: g.paths[node], Node: node, Type: t, References: 2}
+}
+
+// Shared returns the values referred to more than once in g, most referred to first.
+func (g *Graph) Shared() []SharedValue {
+	shared := make([]SharedValue, 0, len(g.shared))
+	for _, s := range g.shared {
+		shared = append(shared, *s)
+	}
+	sort.Slice(shared, func(i, j int) bool {
+		if shared[i].References != shared[j].References {
+			return shared[i].References > shared[j].References
+		}
+		return shared[i].Path < shared[j].Path
+	})
+	return shared
+}
+
+// WriteSharedReport writes the top values of Shared to w, as a table with their number of
+// references, type and path, to find unexpected sharing at a glance.
+func (g *Graph) WriteSharedReport(w io.Writer, top Limit) error {
+	shared := g.Shared()
+	if top >= 0 && len(shared) > int(top) {
+		shared = shared[:top]
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "REFS\tTYPE\tPATH")
+	for _, s := range shared {
+		fmt.Fprintf(tw, "%v\t%v\t%v\n", s.References, s.Type, s.Path)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,127 @@
+package valuegraph
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// Frames beyond this many aren't shown in stack traces.
+const maxStackFrames = 64
+
+// A stackFrame is a line of a stack trace.
+type stackFrame struct {
+	function string
+	location string
+}
+
+// stackTrace returns the rest of the label of stack traces, per Config.StackTraces, as a list
+// of frames, innermost first. Stack traces are slices and arrays of program counters, as
+// filled by runtime.Callers or held by github.com/pkg/errors, and strings formatted like
+// runtime/debug.Stack. The result is escaped for DOT, with left-justified lines.
+func (g *Graph) stackTrace(v reflect.Value, path string) (string, bool) {
+	if !g.cfg.StackTraces {
+		return "", false
+	}
+	var frames []stackFrame
+	var ok bool
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uintptr {
+			frames, ok = callerFrames(v)
+		}
+	case reflect.String:
+		frames, ok = parseStack(v.String())
+	}
+	if !ok {
+		return "", false
+	}
+
+	var s strings.Builder
+	fmt.Fprintf(&s, `\nstack trace, %v frames\n`, len(frames))
+	shown := frames
+	if len(shown) > maxStackFrames {
+		shown = shown[:maxStackFrames]
+	}
+	for i, f := range shown {
+		s.WriteString(escape(fmt.Sprintf("%v %v", i, f.function)) + `\l`)
+		s.WriteString(escape("    "+f.location) + `\l`)
+	}
+	if more := len(frames) - len(shown); more > 0 {
+		g.truncate(path, "stack frame limit reached", "limit", maxStackFrames, "omitted", more)
+		s.WriteString(escape(fmt.Sprintf("... %v more frames", more)) + `\l`)
+	}
+	return s.String(), true
+}
+
+// callerFrames resolves v as program counters. Trailing zeros, as left by runtime.Callers in
+// a larger buffer, are ignored. It fails if any other counter isn't in a known function, so
+// that other uintptrs aren't mistaken for stack traces.
+func callerFrames(v reflect.Value) ([]stackFrame, bool) {
+	n := v.Len()
+	for n > 0 && v.Index(n-1).Uint() == 0 {
+		n--
+	}
+	if n == 0 {
+		return nil, false
+	}
+	pcs := make([]uintptr, n)
+	for i := range pcs {
+		pcs[i] = uintptr(v.Index(i).Uint())
+		// Callers returns return addresses; the call is just before.
+		if pcs[i] == 0 || runtime.FuncForPC(pcs[i]-1) == nil {
+			return nil, false
+		}
+	}
+
+	var frames []stackFrame
+	fs := runtime.CallersFrames(pcs)
+	for {
+		f, more := fs.Next()
+		frames = append(frames, stackFrame{
+			function: f.Function,
+			location: fmt.Sprintf("%v:%v", filepath.Base(f.File), f.Line),
+		})
+		if !more {
+			return frames, true
+		}
+	}
+}
+
+// parseStack parses s as a goroutine stack trace formatted like runtime/debug.Stack: an
+// optional goroutine header, then a function line and a tab-indented file:line line for each
+// frame.
+func parseStack(s string) ([]stackFrame, bool) {
+	if !strings.Contains(s, "\n\t") {
+		return nil, false
+	}
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	if strings.HasPrefix(lines[0], "goroutine ") {
+		lines = lines[1:]
+	}
+	if len(lines) == 0 || len(lines)%2 != 0 {
+		return nil, false
+	}
+	frames := make([]stackFrame, 0, len(lines)/2)
+	for i := 0; i < len(lines); i += 2 {
+		fn, loc := lines[i], lines[i+1]
+		if fn == "" || strings.HasPrefix(fn, "\t") || !strings.HasPrefix(loc, "\t") {
+			return nil, false
+		}
+		// Drop arguments, like "main.f(0x1, ...)", and PC offsets, like "main.go:12 +0x1d".
+		if j := strings.LastIndex(fn, "("); j > 0 && strings.HasSuffix(fn, ")") {
+			fn = fn[:j]
+		}
+		loc = strings.TrimPrefix(loc, "\t")
+		if j := strings.LastIndex(loc, " +0x"); j >= 0 {
+			loc = loc[:j]
+		}
+		if !strings.Contains(loc, ":") {
+			return nil, false
+		}
+		frames = append(frames, stackFrame{function: fn, location: filepath.Base(loc)})
+	}
+	return frames, true
+}
@@ -0,0 +1,131 @@
+package valuegraph
+
+import (
+	"bufio"
+	"io"
+	"reflect"
+	"sort"
+)
+
+// WriteDot writes the graph representation of v to w in dot format while traversing it, instead
+// of building the whole graph in memory first, for values too large to graph twice over.
+// The output shows the same values as Make(v).Dot(), in statements that may be in another
+// order, but a value may get more than one node, and shared values aren't counted.
+//
+// That is because, to keep memory low, only the nodes of values that pointers point to, and of
+// maps, slices and channels, are remembered, which is enough to stop at cycles. So a value
+// reached both through a pointer and in some other way first, like a struct field that a
+// pointer elsewhere points to, gets two nodes. There is no Graph to report shared values on
+// either. Some options keep state for every node, so that memory grows with the graph again: OnEdge
+// keeps the path of every node, MergeEqualLeaves the nodes with children and every distinct
+// leaf, and BreadthFirst and Priority the values waiting to be walked. Slices are always
+// remembered, to show their shared backing arrays at the end.
+//
+// Writing a statement allocates nothing, but building the node it describes still does: its
+// label, path and attributes take about ten allocations per node even for numbers, as they do
+// in Make. BenchmarkWriteDotScalars measures it.
+func (c *Config) WriteDot(w io.Writer, v interface{}) error {
+	return c.WriteDotReflected(w, reflect.ValueOf(v))
+}
+
+// WriteDotReflected is like WriteDot, for a reflected Go value.
+func (c *Config) WriteDotReflected(w io.Writer, v reflect.Value) error {
+	s := &dotStream{w: bufio.NewWriter(w)}
+	g := c.newGraph()
+	g.stream = s
+
+	s.write("digraph G {\n")
+	attrs := graphAttrs(c.Style)
+	for _, k := range sortedKeys(attrs) {
+		s.write("\t" + k + "=" + attrs[k] + ";\n")
+	}
+	g.walk(v, "v")
+	s.write("}\n")
+
+	if s.err != nil {
+		return s.err
+	}
+	if err := s.w.Flush(); err != nil {
+		return err
+	}
+	return g.Err()
+}
+
+// A dotStream writes dot statements, keeping the first error. Statements are appended to buf,
+// which is reused, so that writing one allocates nothing.
+type dotStream struct {
+	w    *bufio.Writer
+	err  error
+	buf  []byte
+	keys []string
+}
+
+func (s *dotStream) write(str string) {
+	if s.err == nil {
+		_, s.err = s.w.WriteString(str)
+	}
+}
+
+// stmt writes a node statement. Attribute values must already be quoted as needed.
+func (s *dotStream) stmt(id string, attrs map[string]string) {
+	s.buf = append(append(s.buf[:0], '\t'), id...)
+	s.writeAttrs(attrs)
+}
+
+// edge writes an edge statement, like stmt.
+func (s *dotStream) edge(src, dst string, attrs map[string]string) {
+	s.buf = append(append(append(append(s.buf[:0], '\t'), src...), "->"...), dst...)
+	s.writeAttrs(attrs)
+}
+
+// writeAttrs writes buf, which holds the start of a statement, followed by attrs.
+func (s *dotStream) writeAttrs(attrs map[string]string) {
+	if len(attrs) > 0 {
+		s.keys = s.keys[:0]
+		for k := range attrs {
+			s.keys = append(s.keys, k)
+		}
+		sort.Strings(s.keys)
+		s.buf = append(s.buf, " [ "...)
+		for i, k := range s.keys {
+			if i > 0 {
+				s.buf = append(s.buf, ", "...)
+			}
+			s.buf = append(append(append(s.buf, k...), '='), attrs[k]...)
+		}
+		s.buf = append(s.buf, " ]"...)
+	}
+	s.buf = append(s.buf, ";\n"...)
+	if s.err == nil {
+		_, s.err = s.w.Write(s.buf)
+	}
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// remembers reports whether the node of v, child of parent, is to be found again by its
+// NodeKey. Streams only remember what's needed to stop at cycles: the graphed value, values
+// that pointers point to, and maps, slices and channels.
+func (g *Graph) remembers(parent string, v reflect.Value, pointee bool) bool {
+	if g.stream == nil || pointee || parent == "G" {
+		return true
+	}
+	switch v.Kind() {
+	case reflect.Map, reflect.Slice, reflect.Chan:
+		return true
+	}
+	return false
+}
+
+// addPointee is like addValue, for the value that a pointer points to.
+func (g *Graph) addPointee(parent string, varName string, v reflect.Value, depth int, limit int, edgeParams map[string]string, path string) {
+	g.pointee = true
+	g.addValue(parent, varName, v, depth, limit, edgeParams, path)
+}
@@ -0,0 +1,62 @@
+package valuegraph
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+type streamList struct {
+	Value int
+	Next  *streamList
+}
+
+func TestWriteDotStopsAtCycles(t *testing.T) {
+	l := &streamList{1, &streamList{2, &streamList{3, nil}}}
+	l.Next.Next.Next = l
+
+	var b bytes.Buffer
+	if err := DefaultConfig.WriteDot(&b, l); err != nil {
+		t.Fatal(err)
+	}
+	want := DefaultConfig.Make(l).Dot()
+	if got, want := strings.Count(b.String(), "label="), strings.Count(want, "label="); got != want {
+		t.Errorf("streamed %v labeled nodes and edges, Make has %v\n%s", got, want, b.String())
+	}
+}
+
+func TestWriteDotKeepsPathsForOnEdge(t *testing.T) {
+	c := *DefaultConfig
+	var froms []string
+	c.OnEdge = func(e *EdgeInfo) { froms = append(froms, e.From) }
+	var b bytes.Buffer
+	if err := c.WriteDot(&b, &streamList{1, nil}); err != nil {
+		t.Fatal(err)
+	}
+	if len(froms) == 0 {
+		t.Fatal("OnEdge not called")
+	}
+	for _, from := range froms {
+		if from == "" {
+			t.Errorf("OnEdge got an edge without a path, in %v", froms)
+		}
+	}
+}
+
+func BenchmarkWriteDotScalars(b *testing.B) {
+	c := *DefaultConfig
+	c.RangeLimit = NoLimit
+	c.NodeLimit = 0
+	v := make([]int, 10000)
+	for i := range v {
+		v[i] = i
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := c.WriteDot(io.Discard, v); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.ReportMetric(float64(testing.AllocsPerRun(1, func() { c.WriteDot(io.Discard, v) }))/float64(len(v)), "allocs/node")
+}
@@ -0,0 +1,42 @@
+package valuegraph
+
+import (
+	"fmt"
+	"strings"
+)
+
+// A TruncatedError is returned in Config.Strict mode by graphs that leave out part of the
+// graphed value because of a limit.
+type TruncatedError struct {
+	// What was left out and why, by path, like "v.Items: range limit reached", in the order
+	// the limits were hit.
+	Truncations []string
+}
+
+func (e *TruncatedError) Error() string {
+	const shown = 5
+	msg := fmt.Sprintf("valuegraph: graph truncated in %v places: ", len(e.Truncations))
+	if len(e.Truncations) <= shown {
+		return msg + strings.Join(e.Truncations, "; ")
+	}
+	return msg + strings.Join(e.Truncations[:shown], "; ") + fmt.Sprintf("; and %v more", len(e.Truncations)-shown)
+}
+
+// Err returns a *TruncatedError if Config.Strict is set and the graph leaves out part of the
+// graphed value because of a limit, or nil otherwise. Methods that render the graph return
+// it instead of rendering; Dot doesn't, so check it first.
+func (g *Graph) Err() error {
+	if len(g.truncations) == 0 {
+		return nil
+	}
+	return &TruncatedError{Truncations: g.truncations}
+}
+
+// truncate records that the value at path is left out, fully or in part, because of reason,
+// and logs it with args as key-value pairs.
+func (g *Graph) truncate(path string, reason string, args ...interface{}) {
+	g.debug("truncated: "+reason, path, args...)
+	if g.cfg.Strict {
+		g.truncations = append(g.truncations, path+": "+reason)
+	}
+}
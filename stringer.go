@@ -0,0 +1,57 @@
+package valuegraph
+
+import (
+	"fmt"
+	"reflect"
+)
+
+var (
+	errorType    = reflect.TypeOf((*error)(nil)).Elem()
+	stringerType = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+)
+
+// stringLabel labels v with its Error or String method, per Config.UseStringers. It returns
+// the rest of the label, escaped for DOT.
+func (g *Graph) stringLabel(v reflect.Value, path string) (string, bool) {
+	if !g.cfg.UseStringers {
+		return "", false
+	}
+	// Pointers and interfaces are followed instead, so that what they point to is still
+	// shared in the graph.
+	if v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		return "", false
+	}
+	for _, it := range []reflect.Type{errorType, stringerType} {
+		x := v
+		if !x.Type().Implements(it) {
+			if !x.CanAddr() || !reflect.PtrTo(x.Type()).Implements(it) {
+				continue
+			}
+			x = x.Addr()
+		}
+		x, ok := g.exposed(x)
+		if !ok {
+			return "", false
+		}
+		s, err := callStringer(x.Interface())
+		if err != nil {
+			s = "(" + err.Error() + ")"
+		} else {
+			s = g.truncateString(s, path)
+		}
+		return escape("\n" + s), true
+	}
+	return "", false
+}
+
+func callStringer(x interface{}) (s string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panicked: %v", r)
+		}
+	}()
+	if e, ok := x.(error); ok {
+		return e.Error(), nil
+	}
+	return x.(fmt.Stringer).String(), nil
+}
@@ -0,0 +1,75 @@
+package valuegraph
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// truncateString cuts s, the value at path, to Config.StringLimit bytes, without splitting
+// UTF-8 sequences, keeping its start, or its start and end if Config.StringTail is set.
+func (g *Graph) truncateString(s string, path string) string {
+	limit := int(g.cfg.StringLimit)
+	if limit < 0 || len(s) <= limit {
+		return s
+	}
+	g.truncate(path, "string limit reached", "limit", limit)
+
+	if !g.cfg.StringTail {
+		head := runeCut(s, limit)
+		return fmt.Sprintf("%v\n... %v more", s[:head], len(s)-head)
+	}
+	head := runeCut(s, (limit+1)/2)
+	tail := len(s) - limit/2
+	for tail < len(s) && !utf8.RuneStart(s[tail]) {
+		tail++
+	}
+	return s[:head] + "…" + s[tail:]
+}
+
+// runeCut returns the largest index up to i at which s can be cut without splitting a UTF-8
+// sequence.
+func runeCut(s string, i int) int {
+	for i > 0 && i < len(s) && !utf8.RuneStart(s[i]) {
+		i--
+	}
+	return i
+}
+
+// paginate returns the first page of s, the string at path, and adds the rest of its pages as
+// a chain of continuation nodes from node, per Config.StringPageSize.
+func (g *Graph) paginate(node string, s string, path string) string {
+	size := g.cfg.StringPageSize
+	end := pageEnd(s, size)
+	first, s := s[:end], s[end:]
+	prev := node
+	for page := 2; s != ""; page++ {
+		if g.full() {
+			g.truncate(path, "node limit reached", "limit", g.cfg.NodeLimit, "omitted", len(s))
+			g.omitted++
+			break
+		}
+		end := pageEnd(s, size)
+		cont := g.nextNode()
+		g.addNode(node, cont, map[string]string{
+			"label":   quote(fmt.Sprintf("(page %v)\n%v", page, s[:end])),
+			"shape":   "box",
+			"style":   "dashed",
+			"tooltip": quote(path),
+		})
+		g.addEdge(prev, cont, map[string]string{"style": "dotted"})
+		prev, s = cont, s[end:]
+	}
+	return first
+}
+
+// pageEnd returns where the first page of s, of up to size bytes, ends, without splitting
+// UTF-8 sequences unless a single one is longer than size.
+func pageEnd(s string, size int) int {
+	if len(s) <= size {
+		return len(s)
+	}
+	if end := runeCut(s, size); end > 0 {
+		return end
+	}
+	return size
+}
@@ -0,0 +1,229 @@
+package valuegraph
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// A Style sets Graphviz attributes on the whole graph.
+type Style struct {
+	// Direction of the layout: "TB" (the default), "LR", "BT" or "RL".
+	RankDir string
+	// Separation between ranks, in inches. 0 means the Graphviz default.
+	RankSep float64
+	// Minimum separation between nodes in the same rank, in inches. 0 means the Graphviz default.
+	NodeSep float64
+	// How edges are drawn: "spline" (the default), "line", "polyline", "ortho" or "curved".
+	Splines string
+	// Resolution of raster formats (PNG, GIF, JPEG), in dots per inch. 0 means the Graphviz
+	// default of 96.
+	DPI float64
+	// Maximum size of the drawing, in inches. Larger drawings are scaled down to fit.
+	// 0 means no maximum.
+	//
+	// Graphviz takes both dimensions or neither, so if only one is set, the other is
+	// approximated as 1000 times it, which no realistic drawing reaches. A "ratio" in Attrs,
+	// like "fill" or "compress", works with that stand-in, not with an unbounded dimension, so
+	// set both when using one.
+	MaxWidth, MaxHeight float64
+	// Colors of the graph. Nil means the Graphviz defaults: black on a transparent background.
+	Theme *Theme
+	// Font of node and edge labels, as a Graphviz font name, like MonospaceFont. Labels with a
+	// font of their own, like hexdumps, keep it. Empty means the Graphviz default.
+	FontName string
+	// Font size of node and edge labels, in points. 0 means the Graphviz default of 14.
+	FontSize float64
+	// Any other graph attributes, by Graphviz name. Values are quoted as needed.
+	Attrs map[string]string
+}
+
+// unboundedSizeFactor is how many times the set one of Style.MaxWidth and Style.MaxHeight the
+// other is taken as, when it isn't set.
+const unboundedSizeFactor = 1000
+
+// graphAttrs returns the graph attributes for the style, with quoted values.
+func graphAttrs(s Style) map[string]string {
+	attrs := map[string]string{}
+	if s.RankDir != "" {
+		attrs["rankdir"] = s.RankDir
+	}
+	if s.RankSep != 0 {
+		attrs["ranksep"] = strconv.FormatFloat(s.RankSep, 'g', -1, 64)
+	}
+	if s.NodeSep != 0 {
+		attrs["nodesep"] = strconv.FormatFloat(s.NodeSep, 'g', -1, 64)
+	}
+	if s.DPI != 0 {
+		attrs["dpi"] = strconv.FormatFloat(s.DPI, 'g', -1, 64)
+	}
+	if s.MaxWidth != 0 || s.MaxHeight != 0 {
+		// Graphviz needs both dimensions; leave the missing one effectively unbounded.
+		w, h := s.MaxWidth, s.MaxHeight
+		if w == 0 {
+			w = h * unboundedSizeFactor
+		}
+		if h == 0 {
+			h = w * unboundedSizeFactor
+		}
+		attrs["size"] = strconv.FormatFloat(w, 'g', -1, 64) + "," + strconv.FormatFloat(h, 'g', -1, 64)
+	}
+	if s.Splines != "" {
+		attrs["splines"] = s.Splines
+	}
+	if s.Theme != nil {
+		attrs["bgcolor"] = s.Theme.Background
+	}
+	for k, v := range s.Attrs {
+		attrs[k] = v
+	}
+	for k, v := range attrs {
+		attrs[k] = quote(v)
+	}
+	return attrs
+}
+
+// MonospaceFont is a font name that Graphviz resolves to a monospace font everywhere, so that
+// values in labels line up.
+const MonospaceFont = "Courier"
+
+// A Theme is a set of colors, applied consistently to the whole graph.
+// Colors are Graphviz color names or "#rrggbb" values.
+type Theme struct {
+	Background string
+	NodeFill   string
+	NodeBorder string
+	Font       string
+	Edge       string
+}
+
+var (
+	Light = &Theme{
+		Background: "#ffffff",
+		NodeFill:   "#ffffff",
+		NodeBorder: "#000000",
+		Font:       "#000000",
+		Edge:       "#000000",
+	}
+	Dark = &Theme{
+		Background: "#1e1e1e",
+		NodeFill:   "#2d2d2d",
+		NodeBorder: "#8c8c8c",
+		Font:       "#d4d4d4",
+		Edge:       "#8c8c8c",
+	}
+	HighContrast = &Theme{
+		Background: "#000000",
+		NodeFill:   "#000000",
+		NodeBorder: "#ffffff",
+		Font:       "#ffffff",
+		Edge:       "#ffff00",
+	}
+)
+
+// styleNode returns the attributes of a node with the parts of the style that Graphviz can't
+// take as graph attributes applied.
+func styleNode(s Style, attrs map[string]string) map[string]string {
+	if s.FontName == "" && s.FontSize == 0 && s.Theme == nil {
+		return attrs
+	}
+	styled := styleCommon(s, attrs)
+	if t := s.Theme; t != nil {
+		if style, ok := styled["style"]; ok {
+			styled["style"] = quote(strings.Trim(style, `"`) + ",filled")
+		} else {
+			styled["style"] = "filled"
+		}
+		styled["fillcolor"] = quote(t.NodeFill)
+		if _, ok := styled["color"]; !ok {
+			styled["color"] = quote(t.NodeBorder)
+		}
+	}
+	return styled
+}
+
+// styleEdge is like styleNode, for edges.
+func styleEdge(s Style, attrs map[string]string) map[string]string {
+	if s.FontName == "" && s.FontSize == 0 && s.Theme == nil {
+		return attrs
+	}
+	styled := styleCommon(s, attrs)
+	if t := s.Theme; t != nil {
+		if _, ok := styled["color"]; !ok {
+			styled["color"] = quote(t.Edge)
+		}
+	}
+	return styled
+}
+
+// styleCommon returns a copy of attrs with the style attributes shared by nodes and edges.
+// Attributes already set, like colors from Config.OnEdge or the font of hexdumps, are kept.
+func styleCommon(s Style, attrs map[string]string) map[string]string {
+	styled := make(map[string]string, len(attrs)+6)
+	for k, v := range attrs {
+		styled[k] = v
+	}
+	setDefault := func(k, v string) {
+		if _, ok := styled[k]; !ok {
+			styled[k] = v
+		}
+	}
+	if s.FontName != "" {
+		setDefault("fontname", quote(s.FontName))
+	}
+	if s.FontSize != 0 {
+		setDefault("fontsize", strconv.FormatFloat(s.FontSize, 'g', -1, 64))
+	}
+	if t := s.Theme; t != nil {
+		setDefault("fontcolor", quote(t.Font))
+	}
+	return styled
+}
+
+// quote makes s a double-quoted DOT string, with newlines as line breaks.
+func quote(s string) string {
+	return `"` + escape(s) + `"`
+}
+
+// escape makes s suitable for the inside of a double-quoted DOT string, with newlines as line
+// breaks. Other control characters and bytes of invalid UTF-8 are shown as \x escapes, and
+// U+FFFD as \ufffd, since DOT parsers reject them.
+func escape(s string) string {
+	if !needsEscape(s) {
+		return s
+	}
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == '\\':
+			b.WriteString(`\\`)
+		case r == '"':
+			b.WriteString(`\"`)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == utf8.RuneError && size == 1:
+			fmt.Fprintf(&b, `\\x%02x`, s[i])
+		case r == utf8.RuneError:
+			b.WriteString(`\\ufffd`)
+		case r != '\t' && (r < 0x20 || r == 0x7f):
+			fmt.Fprintf(&b, `\\x%02x`, r)
+		default:
+			b.WriteString(s[i : i+size])
+		}
+		i += size
+	}
+	return b.String()
+}
+
+// needsEscape reports whether escape would change s.
+func needsEscape(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; c == '\\' || c == '"' || c < 0x20 && c != '\t' || c >= 0x7f {
+			return true
+		}
+	}
+	return false
+}
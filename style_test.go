@@ -0,0 +1,90 @@
+package valuegraph
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStyleKeepsSetAttrs(t *testing.T) {
+	s := Style{Theme: Dark, FontName: "Helvetica", FontSize: 10}
+	for _, tc := range []struct {
+		name  string
+		style func(Style, map[string]string) map[string]string
+		attrs map[string]string
+		want  map[string]string
+	}{{
+		name:  "edge with no colors",
+		style: styleEdge,
+		attrs: map[string]string{},
+		want: map[string]string{
+			"color":     `"#8c8c8c"`,
+			"fontcolor": `"#d4d4d4"`,
+			"fontname":  `"Helvetica"`,
+			"fontsize":  "10",
+		},
+	}, {
+		name:  "edge with colors",
+		style: styleEdge,
+		attrs: map[string]string{"color": `"red"`, "fontcolor": `"blue"`},
+		want: map[string]string{
+			"color":     `"red"`,
+			"fontcolor": `"blue"`,
+			"fontname":  `"Helvetica"`,
+			"fontsize":  "10",
+		},
+	}, {
+		name:  "node with color and font",
+		style: styleNode,
+		attrs: map[string]string{"color": `"#e6194b"`, "fontname": `"Courier"`},
+		want: map[string]string{
+			"color":     `"#e6194b"`,
+			"fillcolor": `"#2d2d2d"`,
+			"fontcolor": `"#d4d4d4"`,
+			"fontname":  `"Courier"`,
+			"fontsize":  "10",
+			"style":     "filled",
+		},
+	}} {
+		if got := tc.style(s, tc.attrs); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestEscape(t *testing.T) {
+	for _, tc := range []struct {
+		s, want string
+	}{
+		{"", ""},
+		{"plain text", "plain text"},
+		{"tab\there", "tab\there"},
+		{`a "quoted" \ back`, `a \"quoted\" \\ back`},
+		{"two\nlines", `two\nlines`},
+		{"bell\a", `bell\\x07`},
+		{"del\x7f", `del\\x7f`},
+		{"bad \xff byte", `bad \\xff byte`},
+		{"replacement �", `replacement \\ufffd`},
+		{"ünïcode", "ünïcode"},
+	} {
+		if got := escape(tc.s); got != tc.want {
+			t.Errorf("escape(%q) = %q, want %q", tc.s, got, tc.want)
+		}
+	}
+}
+
+func TestGraphAttrsSize(t *testing.T) {
+	for _, tc := range []struct {
+		w, h float64
+		want string
+	}{
+		{0, 0, ""},
+		{8, 6, `"8,6"`},
+		{8, 0, `"8,8000"`},
+		{0, 6, `"6000,6"`},
+	} {
+		got := graphAttrs(Style{MaxWidth: tc.w, MaxHeight: tc.h})["size"]
+		if got != tc.want {
+			t.Errorf("MaxWidth %v, MaxHeight %v: got size %q, want %q", tc.w, tc.h, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package valuegraph
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// summarized returns the rest of the label of the slice, array or map v, at path, if it's to
+// be shown as a summary, without children, because Config.RangeLimit or Config.MapLimit is 0.
+// The result is escaped for DOT.
+func (g *Graph) summarized(v reflect.Value, path string) (string, bool) {
+	if v.Len() == 0 {
+		return "", false
+	}
+	ty := v.Type()
+	switch ty.Kind() {
+	case reflect.Slice, reflect.Array:
+		if g.rangeLimit() != 0 {
+			return "", false
+		}
+		g.truncate(path, "summarized: range limit is 0", "omitted", v.Len())
+		return escape("\nelem: " + ty.Elem().String()), true
+	case reflect.Map:
+		if g.mapLimit() != 0 {
+			return "", false
+		}
+		g.truncate(path, "summarized: map limit is 0", "omitted", v.Len())
+		return escape(fmt.Sprintf(" len: %v\nkey: %v, elem: %v", v.Len(), ty.Key(), ty.Elem())), true
+	}
+	return "", false
+}
@@ -0,0 +1,209 @@
+package valuegraph
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+)
+
+var (
+	syncMapType     = reflect.TypeOf(sync.Map{})
+	atomicValueType = reflect.TypeOf(atomic.Value{})
+
+	atomicInt32Type   = reflect.TypeOf(atomic.Int32{})
+	atomicInt64Type   = reflect.TypeOf(atomic.Int64{})
+	atomicUint32Type  = reflect.TypeOf(atomic.Uint32{})
+	atomicUint64Type  = reflect.TypeOf(atomic.Uint64{})
+	atomicUintptrType = reflect.TypeOf(atomic.Uintptr{})
+	atomicBoolType    = reflect.TypeOf(atomic.Bool{})
+)
+
+// syncValue graphs sync.Map, atomic.Value and atomic.Pointer values by their current
+// contents, adding them to node, instead of walking into their internals, which say little.
+// It returns the rest of the label of the node, escaped for DOT.
+func (g *Graph) syncValue(node string, v reflect.Value, depth int, limit int, path string) (string, bool) {
+	ty := v.Type()
+	switch {
+	case ty == syncMapType:
+		m, ok := g.exposedAddr(v)
+		if !ok {
+			return "", false
+		}
+		return g.addSyncMapEntries(node, m.Interface().(*sync.Map), depth, limit, path), true
+	case ty == atomicValueType:
+		x, ok := g.exposedAddr(v)
+		if !ok {
+			return "", false
+		}
+		loaded := x.Interface().(*atomic.Value).Load()
+		if loaded == nil {
+			return ": <nil>", true
+		}
+		g.addValue(node, "", reflect.ValueOf(loaded), depth, limit, nil, path+".Load()")
+		return "", true
+	case ty.PkgPath() == "sync/atomic" && strings.HasPrefix(ty.Name(), "Pointer["):
+		x, ok := g.exposedAddr(v)
+		if !ok {
+			return "", false
+		}
+		loaded := x.MethodByName("Load").Call(nil)[0]
+		if loaded.IsNil() {
+			return ": <nil>", true
+		}
+		g.addPointee(node, "", loaded.Elem(), depth, limit, map[string]string{"style": "dashed"}, path+".Load()")
+		return "", true
+	}
+	return "", false
+}
+
+// exposedAddr returns a pointer to v, or to a copy of it if it isn't addressable, whose
+// Interface method can be called.
+func (g *Graph) exposedAddr(v reflect.Value) (reflect.Value, bool) {
+	x, ok := g.exposed(v)
+	if !ok {
+		return reflect.Value{}, false
+	}
+	return addressable(x).Addr(), true
+}
+
+// addSyncMapEntries adds the entries of m to node, like those of a map, and returns the rest
+// of the label of node.
+func (g *Graph) addSyncMapEntries(node string, m *sync.Map, depth int, limit int, path string) string {
+	var keys, values []reflect.Value
+	m.Range(func(k, v interface{}) bool {
+		keys = append(keys, reflect.ValueOf(k))
+		values = append(values, reflect.ValueOf(v))
+		return true
+	})
+	order := make([]int, len(keys))
+	for i := range order {
+		order[i] = i
+	}
+	if g.cfg.SortMapKeys {
+		// Keys may be of any mix of types, so they can only be sorted by their text.
+		strs := make([]string, len(keys))
+		for i, k := range keys {
+			strs[i] = fmt.Sprintf("%T %v", k.Interface(), g.keyString(k))
+		}
+		sort.SliceStable(order, func(i, j int) bool { return strs[order[i]] < strs[order[j]] })
+	}
+
+	mapLimit := g.mapLimit()
+	if mapLimit == 0 && len(keys) > 0 {
+		g.truncate(path, "summarized: map limit is 0", "omitted", len(keys))
+		return fmt.Sprintf(`\nsync map len: %v`, len(keys))
+	}
+	for i, e := range order {
+		if i == mapLimit {
+			g.addEllipsis(node, len(keys)-i, path, "map limit reached", mapLimit)
+			break
+		}
+		if g.full() {
+			g.truncate(path, "node limit reached", "limit", g.cfg.NodeLimit, "omitted", len(keys)-i)
+			g.omitted += 2 * (len(keys) - i)
+			break
+		}
+		g.addMapEntry(node, keys[e], values[e], depth, limit, path)
+	}
+	return `\nsync map`
+}
+
+func renderAtomicInt32(_ *Config, v reflect.Value) string {
+	return fmt.Sprintf(": %v", addressable(v).Addr().Interface().(*atomic.Int32).Load())
+}
+
+func renderAtomicInt64(_ *Config, v reflect.Value) string {
+	return fmt.Sprintf(": %v", addressable(v).Addr().Interface().(*atomic.Int64).Load())
+}
+
+func renderAtomicUint32(_ *Config, v reflect.Value) string {
+	return fmt.Sprintf(": %v", addressable(v).Addr().Interface().(*atomic.Uint32).Load())
+}
+
+func renderAtomicUint64(_ *Config, v reflect.Value) string {
+	return fmt.Sprintf(": %v", addressable(v).Addr().Interface().(*atomic.Uint64).Load())
+}
+
+func renderAtomicUintptr(_ *Config, v reflect.Value) string {
+	return fmt.Sprintf(": %#x", addressable(v).Addr().Interface().(*atomic.Uintptr).Load())
+}
+
+func renderAtomicBool(_ *Config, v reflect.Value) string {
+	return fmt.Sprintf(": %v", addressable(v).Addr().Interface().(*atomic.Bool).Load())
+}
+
+var (
+	mutexType     = reflect.TypeOf(sync.Mutex{})
+	rwMutexType   = reflect.TypeOf(sync.RWMutex{})
+	waitGroupType = reflect.TypeOf(sync.WaitGroup{})
+	onceType      = reflect.TypeOf(sync.Once{})
+)
+
+// Locks are tried on copies, so that graphing never holds a lock of the program, not even
+// briefly. The copies are racy snapshots, which is as good as any reading of the state.
+
+func renderMutex(_ *Config, v reflect.Value) string {
+	m := lockCopy(v).Interface().(*sync.Mutex)
+	if m.TryLock() {
+		return ": unlocked"
+	}
+	return ": locked"
+}
+
+func renderRWMutex(_ *Config, v reflect.Value) string {
+	m := lockCopy(v).Interface().(*sync.RWMutex)
+	if m.TryLock() {
+		return ": unlocked"
+	}
+	if m.TryRLock() {
+		return ": read-locked"
+	}
+	return ": locked"
+}
+
+func lockCopy(v reflect.Value) reflect.Value {
+	c := reflect.New(v.Type())
+	c.Elem().Set(v)
+	return c
+}
+
+// renderWaitGroup shows the counter of a sync.WaitGroup, which is kept in the high half of
+// its internal state, and the number of waiters, in the low half. It shows nothing if the
+// internals aren't laid out like that.
+func renderWaitGroup(_ *Config, v reflect.Value) string {
+	state, ok := internalField(v, "state")
+	if !ok || state.Type().Size() != 8 {
+		return ""
+	}
+	s := atomic.LoadUint64((*uint64)(unsafe.Pointer(state.UnsafeAddr())))
+	return fmt.Sprintf(": counter %v, waiters %v", int32(s>>32), uint32(s))
+}
+
+// renderOnce shows whether a sync.Once has run, from its internal done flag. It shows
+// nothing if there is no such flag.
+func renderOnce(_ *Config, v reflect.Value) string {
+	done, ok := internalField(v, "done")
+	if !ok {
+		return ""
+	}
+	for _, b := range unsafe.Slice((*byte)(unsafe.Pointer(done.UnsafeAddr())), done.Type().Size()) {
+		if b != 0 {
+			return ": done"
+		}
+	}
+	return ": not done"
+}
+
+// internalField returns the field of v with the given name, or false if there is none. Its
+// UnsafeAddr method can be called.
+func internalField(v reflect.Value, name string) (reflect.Value, bool) {
+	f := addressable(v).FieldByName(name)
+	if !f.IsValid() {
+		return reflect.Value{}, false
+	}
+	return f, true
+}
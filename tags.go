@@ -0,0 +1,18 @@
+package valuegraph
+
+import (
+	"strconv"
+	"strings"
+)
+
+// fieldTags returns the tags of the field f with the keys in Config.ShowTags, as written in
+// the struct type, or an empty string if it has none.
+func (g *Graph) fieldTags(f fieldPlan) string {
+	var tags []string
+	for _, key := range g.cfg.ShowTags {
+		if value, ok := f.tag.Lookup(key); ok {
+			tags = append(tags, key+":"+strconv.Quote(value))
+		}
+	}
+	return strings.Join(tags, " ")
+}
@@ -0,0 +1,42 @@
+package valuegraph
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+// exposed returns a version of v whose Interface method can be called, or false if there is
+// none. Values obtained through unexported struct fields only have one if
+// Config.ReadUnexported is set.
+func (g *Graph) exposed(v reflect.Value) (reflect.Value, bool) {
+	if v.CanInterface() {
+		return v, true
+	}
+	if !g.cfg.ReadUnexported {
+		return reflect.Value{}, false
+	}
+	if v.CanAddr() {
+		return reflect.NewAt(v.Type(), unsafe.Pointer(v.UnsafeAddr())).Elem(), true
+	}
+
+	// Not addressable, like values in maps: copy it into an exported value, which reflect
+	// allows for primitive kinds.
+	c := reflect.New(v.Type()).Elem()
+	switch v.Kind() {
+	case reflect.Bool:
+		c.SetBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		c.SetInt(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		c.SetUint(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		c.SetFloat(v.Float())
+	case reflect.Complex64, reflect.Complex128:
+		c.SetComplex(v.Complex())
+	case reflect.String:
+		c.SetString(v.String())
+	default:
+		return reflect.Value{}, false
+	}
+	return c, true
+}
@@ -0,0 +1,57 @@
+package valuegraph
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+// An unsafeTargetRule is a compiled entry of Config.UnsafePointers.
+type unsafeTargetRule struct {
+	pattern pathPattern
+	target  reflect.Type
+}
+
+func compileUnsafePointers(m map[string]reflect.Type) []unsafeTargetRule {
+	var rules []unsafeTargetRule
+	for p, t := range m {
+		if t == nil {
+			continue
+		}
+		for _, pp := range compilePatterns([]string{p}) {
+			rules = append(rules, unsafeTargetRule{pp, t})
+		}
+	}
+	return rules
+}
+
+// unsafeTarget returns the unsafe.Pointer v at path as a pointer to the type registered for it
+// in Config.UnsafePointers, if any and v isn't nil.
+func (g *Graph) unsafeTarget(path string, v reflect.Value) (reflect.Value, bool) {
+	if len(g.unsafeTargets) == 0 || v.IsNil() {
+		return reflect.Value{}, false
+	}
+	rel := g.relPath(path)
+	for _, r := range g.unsafeTargets {
+		if r.pattern.match(lastName(rel), rel) {
+			return reflect.NewAt(r.target, unsafe.Pointer(v.Pointer())), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// followUnsafe adds the value that the unsafe.Pointer v at path points to as a child of node,
+// if a type is registered for it. It returns the rest of the label of the node.
+func (g *Graph) followUnsafe(node string, v reflect.Value, depth int, limit int, path string) string {
+	p, ok := g.unsafeTarget(path, v)
+	if !ok {
+		return ""
+	}
+	ind := p.Elem()
+	if n, ok := g.nodeOf(ind); ok {
+		g.addShared(n, ind.Type())
+		g.addEdge(node, n, map[string]string{"style": "dashed"})
+	} else {
+		g.addPointee(node, "", ind, depth, limit, map[string]string{"style": "dashed"}, path)
+	}
+	return escape("\n(as " + p.Type().String() + ")")
+}
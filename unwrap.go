@@ -0,0 +1,59 @@
+package valuegraph
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// causeEdge is the style of edges from errors to the errors they wrap.
+var causeEdge = map[string]string{"label": `"cause"`, "style": "bold"}
+
+// unwrap adds the errors wrapped by v, if it's an error, as children of node, per
+// Config.FollowUnwrap.
+func (g *Graph) unwrap(node string, v reflect.Value, depth int, limit int, path string) {
+	if !g.cfg.FollowUnwrap {
+		return
+	}
+	if !v.Type().Implements(errorType) {
+		if !v.CanAddr() || !reflect.PtrTo(v.Type()).Implements(errorType) {
+			return
+		}
+		v = v.Addr()
+	}
+	x, ok := g.exposed(v)
+	if !ok {
+		return
+	}
+	causes, err := callUnwrap(x.Interface().(error))
+	if err != nil {
+		g.addLabeledChild(node, quote("(Unwrap "+err.Error()+")"))
+		return
+	}
+	for i, c := range causes {
+		if c == nil {
+			continue
+		}
+		cpath := path + ".Unwrap()"
+		if len(causes) > 1 {
+			cpath += "[" + strconv.Itoa(i) + "]"
+		}
+		g.debug("following wrapped error", cpath)
+		g.addValue(node, "", reflect.ValueOf(c), depth+1, limit, causeEdge, cpath)
+	}
+}
+
+func callUnwrap(e error) (causes []error, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panicked: %v", r)
+		}
+	}()
+	switch e := e.(type) {
+	case interface{ Unwrap() error }:
+		return []error{e.Unwrap()}, nil
+	case interface{ Unwrap() []error }:
+		return e.Unwrap(), nil
+	}
+	return nil, nil
+}
@@ -2,33 +2,293 @@
 package valuegraph
 
 import (
-	"errors"
+	"container/heap"
+	"context"
 	"fmt"
+	"image"
+	"image/png"
 	"io/ioutil"
-	"os"
-	"os/exec"
+	"log/slog"
+	"math/rand"
 	"path/filepath"
 	"reflect"
-	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/awalterschulze/gographviz"
 	"github.com/tcard/valuegraph/gographvizutil"
 )
 
+// ErrDotNotFound is returned by methods that need the dot command when it isn't installed.
+// Applications can check IsDotAvailable up front and fall back to the Dot method instead.
+var ErrDotNotFound = gographvizutil.ErrNoDot
+
+// IsDotAvailable reports whether the dot command, needed for rendering, is available in the system.
+func IsDotAvailable() bool {
+	return gographvizutil.IsDotAvailable()
+}
+
 // A Config tweaks the generation of a Graph.
+//
+// Struct fields can also be controlled with a valuegraph struct tag: a name to show instead of
+// the field's, optionally followed by ",redact" to hide its value or ",collapse" to not walk
+// into it, like `valuegraph:"token,redact"` or `valuegraph:",collapse"`. A tag of "-" skips
+// the field, whatever follows it.
 type Config struct {
-	// Generate up to this many child nodes per slice or array, to reduce noise. -1 means no limit.
-	RangeLimit int
-	// Generate up to this many child nodes per map, to reduce noise. -1 means no limit.
-	MapLimit int
-	// Truncate strings to this length. -1 means no limit.
-	StringLimit int
-	// Stop walking inside compound data structures after reaching this many levels. -1 means no limit.
-	DepthLimit int
+	// Generate up to this many child nodes per slice or array, to reduce noise. 0 shows just
+	// their length and element type, without children.
+	RangeLimit Limit
+	// Which elements are shown of slices and arrays longer than RangeLimit. The zero value is
+	// SampleHead.
+	Sampling Sampling
+	// Where the node summarizing the elements left out of slices and arrays goes. The zero
+	// value is EllipsisInPlace.
+	Ellipsis EllipsisPosition
+	// Seed of SampleRandom, so that the same graph gets the same sample. 0 means a different
+	// seed each time.
+	SampleSeed int64
+	// Generate up to this many child nodes per map, to reduce noise. 0 shows just their
+	// length and key and element types, without children.
+	MapLimit Limit
+	// Walk map entries sorted by key, by value for numbers, strings and bools, and by their
+	// formatted string otherwise, so that graphing the same map gives the same graph, and
+	// MapLimit keeps the same entries.
+	SortMapKeys bool
+	// If not empty, only the entries with these keys are graphed of maps with keys of their
+	// type, like []interface{}{"id", "name"}, looked up directly. Maps with keys of other types
+	// are graphed as usual.
+	MapKeys []interface{}
+	// If not nil, only the map entries whose key it returns true for are graphed.
+	MapKeyFilter func(key reflect.Value) bool
+	// Truncate strings to this length, in bytes, without splitting characters.
+	StringLimit Limit
+	// Show both the start and the end of strings longer than StringLimit, like "abcd…wxyz",
+	// instead of only their start.
+	StringTail bool
+	// If positive, strings are shown whole instead of truncated to StringLimit, split in pages
+	// of this many bytes, each in a node chained to the previous one, for long strings like
+	// SQL queries or stack traces.
+	StringPageSize int
+	// Stop walking inside compound data structures after reaching this many levels.
+	DepthLimit Limit
+	// Generate up to about this many nodes in total; the values that don't fit are summarized
+	// in a single node. Less than 1 means no limit.
+	NodeLimit int
+	// Overrides DepthLimit inside values of the given types: their children are walked up to
+	// this many levels deep, or without limit if NoLimit. 0 shows values of the type without
+	// walking inside them at all.
+	TypeDepthLimits map[reflect.Type]Limit
+	// Types whose values are graphed as a single node, without walking into them, like
+	// reflect.TypeOf(&sql.DB{}). Useful for types whose internals explode the graph.
+	OpaqueTypes []reflect.Type
+	// Like OpaqueTypes, by name, either as printed with the package name, like
+	// "*grpc.ClientConn", or with the full import path, like "*google.golang.org/grpc.ClientConn".
+	OpaqueTypeNames []string
+	// Show the values of unexported struct fields, which reflect doesn't normally give access to.
+	// Addressable values are read through unsafe; others are copied if primitive.
+	ReadUnexported bool
+	// Don't graph unexported struct fields, showing only the exported surface of types.
+	SkipUnexported bool
+	// If not empty, only struct fields matching one of these patterns are graphed, along with
+	// the fields they are inside of. Patterns are field names, paths with * wildcards like
+	// "Users[*].Token", or regular expressions between slashes like "/Token$/".
+	IncludeFields []string
+	// Struct fields matching one of these patterns aren't graphed. They take precedence over
+	// IncludeFields.
+	ExcludeFields []string
+	// Values matching one of these patterns, as in IncludeFields, like "Password" or
+	// "*.APIKey", are graphed as nodes labeled «redacted», without their contents, so that
+	// graphs can be shared without leaking secrets. Values of types implementing Sensitive
+	// always are.
+	Redact []string
+	// Graph private keys, like *rsa.PrivateKey, *ecdsa.PrivateKey and ed25519.PrivateKey, and
+	// tls.Certificate values, which hold them. Otherwise, they are graphed as octagons labeled
+	// «private key», without their contents.
+	ShowKeyMaterial bool
+	// If not nil, called for each value before walking into it, with its path and depth.
+	// Values it returns true for are graphed without walking into them.
+	Skip func(path string, depth int, v reflect.Value) bool
+	// If not nil, called for each value with its path, and values it returns true for, like
+	// nil pointers or negative balances, are graphed as nodes with a thick colored border, to
+	// stand out in large graphs.
+	Highlight func(path string, v reflect.Value) bool
+	// If not nil, called for each node of a value before adding it to the graph, and able to
+	// change its label and attributes, like to highlight it.
+	OnNode func(n *NodeInfo)
+	// If not nil, called for each edge before adding it to the graph, and able to change its
+	// attributes.
+	OnEdge func(e *EdgeInfo)
+	// If positive, numbers are graphed with random noise added, to share graphs of
+	// semi-sensitive data: each is perturbed by Laplace noise scaled by this fraction of its
+	// magnitude, like 0.1 for about 10%, so that magnitudes stay plausible. Zeros are kept.
+	// Perturbed values are marked with ≈.
+	Noise float64
+	// Seed of the noise added per Noise, so that the same graph gets the same noise. 0 means
+	// a different seed each time.
+	NoiseSeed int64
+	// Graph values of types implementing error or fmt.Stringer as a single node labeled with
+	// the result of their Error or String method, limited by StringLimit, instead of walking
+	// into them. Pointers are still followed. DefaultConfig has it set.
+	UseStringers bool
+	// Along with UseStringers, graph the errors wrapped by errors, as returned by their
+	// Unwrap() error or Unwrap() []error methods, linked by edges labeled "cause".
+	FollowUnwrap bool
+	// Don't graph struct fields holding the zero value of their type, like 0, "", false, nil
+	// or structs with all fields zero.
+	HideZero bool
+	// Don't graph struct fields holding nil pointers, slices, maps, interfaces, channels or
+	// functions. HideZero implies this.
+	HideNil bool
+	// Layout of time.Time values, as accepted by their Format method. Empty means
+	// time.RFC3339Nano. Their location is shown too.
+	TimeLayout string
+	// If positive, []bool, [N]bool, big.Int and bitset values (those with methods Len() uint
+	// and Test(uint) bool) are drawn as strips of blocks, this many bits per line, instead of
+	// being walked into.
+	BitmapWidth int
+	// Graph stack traces as a single node listing their frames, with function and file:line,
+	// instead of as slices of numbers or long strings. Stack traces are slices and arrays of
+	// uintptr program counters, as filled by runtime.Callers or held by github.com/pkg/errors,
+	// and strings formatted like runtime/debug.Stack. DefaultConfig has it set.
+	StackTraces bool
+	// If positive, chains of pointers from structs to structs of the same type, like linked
+	// lists or the spines of trees, longer than this many elements are cut: only their first
+	// ChainLimit elements and their last one are graphed, with a node counting the rest.
+	ChainLimit int
+	// Graph the fields of embedded structs as fields of the structs embedding them, as they are
+	// promoted, instead of as a child node for each embedded struct. Promoted fields shadowed by
+	// fields of the embedding struct are labeled with the embedded struct's name, like
+	// "Base.ID". Embedded pointers, and embedded structs graphed as a single node, like
+	// time.Time, aren't inlined.
+	InlineEmbedded bool
+	// List fields holding booleans, numbers and strings as lines of the label of their struct's
+	// node, like "ID: 42", instead of as a child node each, which takes several times fewer
+	// nodes. Fields graphed as several lines, and those matched by Highlight, still get their
+	// own nodes. Folded fields aren't passed to OnNode.
+	CompactScalars bool
+	// Show whether channels are closed, when that can be told without receiving a value from
+	// them: for buffered channels that can be received from and are empty. This tries to
+	// receive from them, so a value sent concurrently may be received, and lost, instead.
+	// Unbuffered channels are never probed, since that would take the value of any sender
+	// blocked on them.
+	ProbeChannels bool
+	// Show the addresses of pointers, maps, slices and channels in their nodes, as printed by
+	// the %p verb, to match graphs with logs.
+	ShowAddresses bool
+	// Graph values with no children and the same type and label, like the same enum value in
+	// many rows, as a single node with an edge from each parent, labeled with the name the value
+	// has there. Ignored if OnNode is set, since it sees each value separately.
+	MergeEqualLeaves bool
+	// Graph runs of consecutive equal elements of slices and arrays, as by ==, like the zeros
+	// of a fresh buffer, as a single node with an edge labeled with their count, like ×1024.
+	// Each run counts as one element for RangeLimit, and is cut after 65536 elements. Not
+	// applied with SampleRandom.
+	FoldRuns bool
+	// Link the nodes of values whose types have exported methods to a node listing them, with
+	// their signatures, shared by the values of each type.
+	ShowMethods bool
+	// Keys of the struct tags shown along with the names of fields, like "json" or "db", as
+	// json:"id,omitempty". Fields without a tag with the key don't show it.
+	ShowTags []string
+	// Show int32 and uint8 values, like runes and bytes, along with the character they stand
+	// for, quoted, if it's printable, and ASCII for uint8, as in int32: 65 'A'.
+	ShowRunes bool
+	// If positive, byte slices and arrays are shown as a hexdump of up to this many bytes, with
+	// an ASCII column, in a single node. DefaultConfig has 256.
+	HexdumpLimit int
+	// Binary layouts of []byte values, by field pattern as in IncludeFields. Matching values are
+	// graphed as the fields in the layout, decoded, instead of as bytes.
+	ByteLayouts map[string]ByteLayout
+	// Types that unsafe.Pointer values point to, by field pattern as in IncludeFields, like
+	// reflect.TypeOf(Node{}). Matching pointers that aren't nil are followed, and what they
+	// point to is graphed as a value of that type. Otherwise unsafe.Pointer values are shown
+	// as addresses only.
+	//
+	// This reads memory as the registered type without any check, so it's only as safe as
+	// the registrations: a pointer to anything else, or to freed memory, gives garbage and
+	// may crash the program. Only register pointers that always point to that type.
+	UnsafePointers map[string]reflect.Type
+	// Decimal and money types graphed as the amount they hold, checked in order. The first
+	// that matches a type is used. DefaultConfig has CommonAmounts.
+	Amounts []AmountType
+	// Interface types whose implementations are grouped together in the graph: each value
+	// whose type implements one of them, checked in order, is drawn inside a box labeled
+	// with the interface. Get them like reflect.TypeOf((*io.Reader)(nil)).Elem().
+	ClusterInterfaces []reflect.Type
+	// Graphviz layout engine used when rendering. Empty means dot. Dense graphs with many
+	// pointer back-edges are often more readable with Sfdp or Neato.
+	Layout gographvizutil.Layout
+	// Renderer used to run dot. Nil means running a dot process per render. Use a
+	// gographvizutil.Cache to reuse outputs for identical graphs, or a gographvizutil.Batcher
+	// to share dot processes between concurrent renders.
+	Renderer gographvizutil.Renderer
+	// Order in which compound data structures are walked. The zero value is DepthFirst.
+	Order Order
+	// If positive, graph at most this many values at each depth; the children left out are
+	// summarized in a "... N more" node under their parent. With BreadthFirst, each level is
+	// shared out across the whole structure in order, so that wide structures get a balanced
+	// overview, instead of DepthFirst spending it inside their first branches.
+	LevelLimit int
+	// If not nil, values are walked in decreasing order of priority, with ties walked
+	// BreadthFirst, so that the most interesting regions survive when limits truncate the graph.
+	// It's called once per value, with its path.
+	Priority func(path string, v reflect.Value) float64
+	// Graph-level layout attributes, like direction and spacing.
+	Style Style
+	// Command used by OpenSVG and friends to open the written file, which is passed as the
+	// last argument. Arguments are separated by spaces, except inside double or single quotes,
+	// like `"C:\Program Files\Viewer\viewer.exe" /new`. Empty means trying the commands in
+	// the BROWSER environment variable and then some known viewers.
+	Viewer string
+	// Directory where OpenSVG and friends write their files. Empty means os.TempDir().
+	TempDir string
+	// Name of the files written by OpenSVG and friends, without extension. The last "*" is
+	// replaced by a random string, which is appended if there is no "*". Empty means
+	// "valuegraph-*".
+	TempPattern string
+	// If positive, files that OpenSVG and friends wrote in TempDir with a TempTTL, and more
+	// than this long ago, are removed whenever they write a new one. Other files are never
+	// removed: written files are recorded in a ".valuegraph" subdirectory of TempDir. See also
+	// CleanupTempFiles.
+	TempTTL time.Duration
+	// Time budget for Make. As it runs out, the graph gets progressively less detailed instead
+	// of taking longer: past half of it, slices, arrays and maps are summarized without
+	// children; past three quarters, pointers to values not yet in the graph aren't followed;
+	// past all of it, remaining values are cut off. 0 means no deadline.
+	Deadline time.Duration
+	// Make every limit above, and any other reason part of the value is left out of the graph,
+	// an error instead: see Graph.Err and TruncatedError. For graphs used as authoritative
+	// records of values, which must not silently elide data.
+	Strict bool
+	// Name of the configuration, used to label profiles taken while running MakeContext.
+	Name string
+	// If not nil, decisions made while walking values are logged here at debug level, with the
+	// path of the value they affect: why subtrees were truncated or left out, and which options
+	// or registered handlers decided how values are graphed.
+	Logger *slog.Logger
+	// Names of the fields that Merge takes from this Config even if they are the zero value.
+	Explicit []string
+
+	// Set with RegisterFormatter and RegisterInterfaceFormatter.
+	formatters      map[reflect.Type]Formatter
+	ifaceFormatters []ifaceFormatter
 }
 
+// An Order is the order in which the values inside compound data structures are walked.
+type Order int
+
+const (
+	// DepthFirst walks each child completely before moving on to its next sibling.
+	DepthFirst Order = iota
+	// BreadthFirst walks every value at one level before moving on to the next, so that
+	// limits on the size of the graph are spent evenly across the top of the structure
+	// instead of down a single deep branch.
+	BreadthFirst
+)
+
 // Make constructs a Graph representation of any Go value, for inspection.
 func (c *Config) Make(v interface{}) *Graph {
 	return c.MakeReflected(reflect.ValueOf(v))
@@ -36,24 +296,97 @@ func (c *Config) Make(v interface{}) *Graph {
 
 // MakeReflected constructs a Graph representation of any reflected Go value, for inspection.
 func (c *Config) MakeReflected(v reflect.Value) *Graph {
-	g := &Graph{Graph: gographviz.NewGraph(), Nodes: make(map[reflect.Value]string), cfg: c}
+	g := c.newGraph()
+	g.Graph = gographviz.NewGraph()
 	g.SetName("G")
 	g.SetDir(true)
-	g.addValue("G", "", v, 0, nil, "v")
+	for k, v := range graphAttrs(c.Style) {
+		g.AddAttr("G", k, v)
+	}
+	g.walk(v, "v")
 	return g
 }
 
+func (c *Config) newGraph() *Graph {
+	return &Graph{
+		Nodes:         make(map[NodeKey]string),
+		cfg:           c,
+		start:         time.Now(),
+		include:       compilePatterns(c.IncludeFields),
+		exclude:       compilePatterns(c.ExcludeFields),
+		redact:        compilePatterns(c.Redact),
+		byteLayouts:   compileByteLayouts(c.ByteLayouts),
+		unsafeTargets: compileUnsafePointers(c.UnsafePointers),
+		amountTypes:   make(map[reflect.Type]*AmountType),
+		paths:         make(map[string]string),
+		shared:        make(map[string]*SharedValue),
+		hasChildren:   make(map[string]bool),
+		leaves:        make(map[string]string),
+		methodNodes:   make(map[reflect.Type]string),
+		chainCuts:     make(map[NodeKey]*chainCut),
+		chainLinks:    make(map[NodeKey]bool),
+		levels:        make(map[int]int),
+		levelCuts:     make(map[string]int),
+	}
+}
+
+func (g *Graph) walk(v reflect.Value, path string) {
+	g.root = path
+	if g.cfg.ReadUnexported && v.IsValid() && !v.CanAddr() {
+		// Make the fields inside structs held by value addressable, so that they can be read.
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v)
+		v = c
+	}
+	g.addValue("G", "", v, 0, int(g.cfg.DepthLimit), nil, path)
+	for g.queue.Len() > 0 {
+		p := heap.Pop(&g.queue).(pending)
+		g.visit(p.node, p.parent, p.varName, p.v, p.depth, p.limit, p.edgeParams, p.path)
+	}
+	g.addBackingArrays()
+	g.addLevelEllipses()
+	if g.omitted > 0 {
+		g.addTruncated("G", g.nextNode(), fmt.Sprintf("... %v values omitted (node limit %v reached)", g.omitted, g.cfg.NodeLimit))
+		g.omitted = 0
+	}
+}
+
 var DefaultConfig = &Config{
-	RangeLimit:  5,
-	MapLimit:    -1,
-	StringLimit: 30,
-	DepthLimit:  -1,
+	Name:         "default",
+	RangeLimit:   5,
+	MapLimit:     NoLimit,
+	StringLimit:  30,
+	DepthLimit:   NoLimit,
+	Amounts:      CommonAmounts,
+	UseStringers: true,
+	HexdumpLimit: 256,
+	StackTraces:  true,
+}
+
+// MakeContext is like Make, but attributes the time spent to valuegraph in profiles and traces
+// of the calling program: the traversal runs with pprof labels valuegraph.type (the type of v)
+// and valuegraph.config (c.Name), added to those in ctx, and inside a runtime/trace region.
+func (c *Config) MakeContext(ctx context.Context, v interface{}) *Graph {
+	ty := "<nil>"
+	if v != nil {
+		ty = reflect.TypeOf(v).String()
+	}
+	var g *Graph
+	pprof.Do(ctx, pprof.Labels("valuegraph.type", ty, "valuegraph.config", c.Name), func(ctx context.Context) {
+		trace.WithRegion(ctx, "valuegraph.Make", func() {
+			g = c.Make(v)
+		})
+	})
+	return g
 }
 
 // Make constructs a Graph representation of any Go value, for inspection.
-// It uses DefaultConfig.
-func Make(v interface{}) *Graph {
-	return DefaultConfig.Make(v)
+// It uses DefaultConfig, with opts applied.
+func Make(v interface{}, opts ...Option) *Graph {
+	if len(opts) == 0 {
+		return DefaultConfig.Make(v)
+	}
+	return DefaultConfig.With(opts...).Make(v)
 }
 
 // MakeReflected constructs a Graph representation of any reflected Go value, for inspection.
@@ -66,9 +399,56 @@ func MakeReflected(v reflect.Value) *Graph {
 // A Graph representation of some value.
 type Graph struct {
 	*gographviz.Graph
-	Nodes map[reflect.Value]string
+	Nodes map[NodeKey]string
 	cfg   *Config
 	i     int
+	queue pendingQueue
+	seq   int
+	start time.Time
+	// If not nil, nodes and edges are written here instead of to Graph.
+	stream *dotStream
+	// Set while adding a value that a pointer points to, whose node streams remember.
+	pointee bool
+	// If not empty, the subgraph new nodes are added to.
+	cluster string
+	// Values left out because of Config.NodeLimit.
+	omitted int
+	// Path of the graphed value, and compiled field filters.
+	root             string
+	include, exclude []pathPattern
+	redact           []pathPattern
+	byteLayouts      []byteLayoutRule
+	unsafeTargets    []unsafeTargetRule
+	// Path of each value's node.
+	paths map[string]string
+	// Values referred to more than once, by node.
+	shared map[string]*SharedValue
+	// Nodes with edges from them, or values scheduled as children, and nodes of leaves by
+	// label and attributes, for Config.MergeEqualLeaves.
+	hasChildren map[string]bool
+	leaves      map[string]string
+	// Nodes listing the methods of each type, per Config.ShowMethods.
+	methodNodes map[reflect.Type]string
+	// Where chains are cut per Config.ChainLimit, by pointer, and pointers in chains already
+	// looked for cuts.
+	chainCuts  map[NodeKey]*chainCut
+	chainLinks map[NodeKey]bool
+	// Values graphed at each depth, and values left out by parent, in order, per
+	// Config.LevelLimit.
+	levels          map[int]int
+	levelCuts       map[string]int
+	levelCutParents []string
+	// Backing arrays of the slices graphed in the current walk.
+	arrays []*backingArray
+	// Config.Amounts entry of each type seen, or nil if none.
+	amountTypes map[reflect.Type]*AmountType
+	// Config.OpaqueTypes and Config.OpaqueTypeNames as sets, built on first use.
+	opaqueTypes map[reflect.Type]bool
+	opaqueNames map[string]bool
+	// What was left out because of limits, if Config.Strict is set.
+	truncations []string
+	// Sources of Config.Noise and SampleRandom, created on first use.
+	rand, sampleSource *rand.Rand
 }
 
 func (g *Graph) nextNode() string {
@@ -77,162 +457,441 @@ func (g *Graph) nextNode() string {
 	return s
 }
 
-func (g *Graph) addValue(parent string, varName string, v reflect.Value, depth int, edgeParams map[string]string, path string) {
+func (g *Graph) full() bool {
+	return g.cfg.NodeLimit > 0 && g.i >= g.cfg.NodeLimit
+}
+
+func (g *Graph) addValue(parent string, varName string, v reflect.Value, depth int, limit int, edgeParams map[string]string, path string) {
+	pointee := g.pointee
+	g.pointee = false
+	if g.full() {
+		g.truncate(path, "node limit reached", "limit", g.cfg.NodeLimit)
+		g.omitted++
+		return
+	}
+	if g.cfg.MergeEqualLeaves {
+		g.hasChildren[parent] = true
+	}
+	k, hasKey := nodeKey(v)
+	if n, ok := g.Nodes[k]; ok && hasKey && parent != "G" {
+		g.addSharedEdge(parent, varName, n, v, edgeParams, path)
+		return
+	}
+	if g.levelFull(parent, depth, path) {
+		return
+	}
 	node := g.nextNode()
-	g.Nodes[v] = node
+	if hasKey && g.remembers(parent, v, pointee) {
+		g.Nodes[k] = node
+	}
+	if g.stream == nil || g.cfg.OnEdge != nil {
+		g.paths[node] = path
+	}
 
-	if depth == g.cfg.DepthLimit {
-		g.AddNode(parent, node, map[string]string{
-			"label": fmt.Sprintf(`"(depth limit %v reached)"`, g.cfg.DepthLimit),
-			"shape": "box",
-		})
+	if g.cfg.Order == BreadthFirst || g.cfg.Priority != nil {
+		p := pending{node: node, parent: parent, varName: varName, v: v, depth: depth, limit: limit, edgeParams: edgeParams, path: path, seq: g.seq}
+		g.seq++
+		if g.cfg.Priority != nil {
+			p.priority = g.cfg.Priority(path, v)
+		}
+		heap.Push(&g.queue, p)
+		return
+	}
+	g.visit(node, parent, varName, v, depth, limit, edgeParams, path)
+}
 
-		if parent != "G" {
-			g.AddEdge(parent, node, true, nil)
+func (g *Graph) visit(node string, parent string, varName string, v reflect.Value, depth int, limit int, edgeParams map[string]string, path string) {
+	typeLimit, hasTypeLimit := NoLimit, false
+	if v.IsValid() {
+		typeLimit, hasTypeLimit = g.cfg.TypeDepthLimits[v.Type()]
+	}
+	if hasTypeLimit && typeLimit >= 0 {
+		limit = depth + int(typeLimit) + 1
+	} else if hasTypeLimit {
+		limit = -1
+	}
+
+	if depth == limit {
+		if limit == int(g.cfg.DepthLimit) {
+			g.truncate(path, "depth limit reached", "limit", g.cfg.DepthLimit)
+			g.addTruncated(parent, node, cutLabel(varName, v, fmt.Sprintf("(depth limit %v reached)", g.cfg.DepthLimit)))
+		} else {
+			g.truncate(path, "type depth limit reached", "type", v.Type())
+			g.addTruncated(parent, node, cutLabel(varName, v, "(type depth limit reached)"))
 		}
 		return
 	}
+	if g.pressure() >= stopWalking {
+		g.truncate(path, "deadline reached", "deadline", g.cfg.Deadline)
+		g.addTruncated(parent, node, cutLabel(varName, v, "(deadline reached)"))
+		return
+	}
 
-	nodeParams := map[string]string{"shape": "box", "tooltip": `"` + path + `"`}
+	nodeParams := map[string]string{"shape": "box", "tooltip": quote(path)}
 
 	label := ""
 	if varName != "" {
-		label = varName + `\n`
+		label = escape(varName) + `\n`
 	}
-
-	if v.Kind() != reflect.Invalid {
+	nameLen := len(label)
+
+	if hasTypeLimit && typeLimit == 0 {
+		g.truncate(path, "not expanded: type depth limit is 0", "type", v.Type())
+		label += planFor(v.Type()).name + `\n(not expanded)`
+	} else if g.skipped(path, depth, v) {
+		g.debug("not expanded: Skip", path)
+		label += planFor(v.Type()).name + `\n(not expanded)`
+	} else if g.redacted(path, v) {
+		g.debug("redacted", path)
+		label += planFor(v.Type()).name + `\n«redacted»`
+	} else if g.keyMaterial(v) {
+		g.debug("redacted: key material", path)
+		label += planFor(v.Type()).name + `\n«private key»`
+		nodeParams["shape"] = "octagon"
+	} else if v.Kind() != reflect.Invalid {
 		ty := v.Type()
-		label += ty.String()
-		switch ty.Kind() {
-		case reflect.Bool,
-			reflect.Int,
-			reflect.Int8,
-			reflect.Int16,
-			reflect.Int32,
-			reflect.Int64,
-			reflect.Uint,
-			reflect.Uint8,
-			reflect.Uint16,
-			reflect.Uint32,
-			reflect.Uint64,
-			reflect.Uintptr,
-			reflect.Float32,
-			reflect.Float64,
-			reflect.Complex64,
-			reflect.Complex128,
-			reflect.UnsafePointer,
-			reflect.Chan,
-			reflect.Func:
-			label += `: ` + fmt.Sprint(v.Interface())
-		case reflect.Interface:
-			label += `\ninterface`
-			nodeParams["style"] = "dashed"
-			if v.IsNil() {
-				label += ": <nil>"
-			} else {
-				g.addValue(node, "", v.Elem(), depth+1, map[string]string{
-					"style":     "dashed",
-					"arrowhead": "empty",
-				}, path+fmt.Sprintf(".(%v)", v.Elem().Type()))
-			}
-		case reflect.String:
-			label += fmt.Sprintf(" len: %v", v.Len())
-			s := v.String()
-			if len(s) > g.cfg.StringLimit {
-				s = s[:g.cfg.StringLimit]
-			}
-			s = strings.Replace(s, `\`, `\\`, -1)
-			s = strings.Replace(s, `"`, `\"`, -1)
-			label += "\n" + s
-			if v.Len() > g.cfg.StringLimit {
-				label += fmt.Sprintf("\n... %v more", v.Len()-10)
-			}
-		case reflect.Array:
-			label += `\narray`
-			l := v.Len()
-			label += fmt.Sprintf(" len: %v", l)
-			for i := 0; i < l; i++ {
-				if i == g.cfg.RangeLimit {
-					g.addEllipsis(node, l-i)
+		plan := planFor(ty)
+		label += plan.name + g.address(v)
+		if leaf, handler, ok := g.leafLabel(v, path); ok {
+			g.debug("graphed as a single node", path, "type", ty, "handler", handler)
+			label += leaf
+		} else if custom, ok := g.graphValue(node, v, depth, limit, path); ok {
+			g.debug("graphed by its GraphValue method", path, "type", ty)
+			label += custom
+		} else if s, ok := g.syncValue(node, v, depth, limit, path); ok {
+			g.debug("graphed by its current contents", path, "type", ty)
+			label += s
+		} else if s, ok := g.stringLabel(v, path); ok {
+			g.debug("graphed as a single node", path, "type", ty, "handler", "UseStringers")
+			label += s
+			g.unwrap(node, v, depth, limit, path)
+		} else {
+			switch ty.Kind() {
+			case reflect.Bool,
+				reflect.Int,
+				reflect.Int8,
+				reflect.Int16,
+				reflect.Int32,
+				reflect.Int64,
+				reflect.Uint,
+				reflect.Uint8,
+				reflect.Uint16,
+				reflect.Uint32,
+				reflect.Uint64,
+				reflect.Uintptr,
+				reflect.Float32,
+				reflect.Float64,
+				reflect.Complex64,
+				reflect.Complex128:
+				if x, ok := g.exposed(v); ok {
+					if n, ok := g.noisy(x); ok {
+						label += `: ≈` + fmt.Sprint(n.Interface())
+					} else if s, ok := appendScalar(label, x); ok {
+						label = s + g.runeSuffix(x)
+					} else {
+						label += `: ` + fmt.Sprint(x.Interface()) + g.runeSuffix(x)
+					}
+				} else {
+					label += `: (unexported)`
 				}
-				idx := "[" + strconv.Itoa(i) + "]"
-				g.addValue(node, idx, v.Index(i), depth+1, nil, path+idx)
-			}
-		case reflect.Map:
-			label += `\nmap`
-			if v.IsNil() {
-				label += ": <nil>"
-			} else {
-				keys := v.MapKeys()
-				i := 0
-				for _, k := range keys {
-					if i == g.cfg.MapLimit {
-						g.addEllipsis(node, v.Len()-i)
-						break
+			case reflect.UnsafePointer:
+				if x, ok := g.exposed(v); ok {
+					label += `: ` + fmt.Sprint(x.Interface())
+				} else {
+					label += `: (unexported)`
+				}
+				label += g.followUnsafe(node, v, depth, limit, path)
+			case reflect.Chan:
+				label += g.chanLabel(v)
+			case reflect.Func:
+				label += g.funcLabel(v)
+			case reflect.Interface:
+				label += `\ninterface`
+				nodeParams["style"] = "dashed"
+				if v.IsNil() {
+					label += ": <nil>"
+				} else {
+					g.addValue(node, "", v.Elem(), depth+1, limit, map[string]string{
+						"style":     "dashed",
+						"arrowhead": "empty",
+					}, path+fmt.Sprintf(".(%v)", v.Elem().Type()))
+				}
+			case reflect.String:
+				label += fmt.Sprintf(" len: %v", v.Len())
+				if g.cfg.StringPageSize > 0 {
+					label += `\n` + escape(g.paginate(node, v.String(), path))
+				} else {
+					label += `\n` + escape(g.truncateString(v.String(), path))
+				}
+			case reflect.Array:
+				label += `\narray`
+				l := v.Len()
+				label += fmt.Sprintf(" len: %v", l)
+				if dump, ok := g.hexdump(v, path); ok {
+					label += dump
+					nodeParams["fontname"] = quote(MonospaceFont)
+					break
+				}
+				if s, ok := g.summarized(v, path); ok {
+					label += s
+					break
+				}
+				g.addElements(node, v, depth, limit, path)
+			case reflect.Map:
+				label += `\nmap`
+				if v.IsNil() {
+					label += ": <nil>"
+				} else if s, ok := g.summarized(v, path); ok {
+					label += s
+				} else {
+					keys := g.selectKeys(v)
+					g.sortKeys(keys)
+					mapLimit := g.mapLimit()
+					i := 0
+					for _, k := range keys {
+						if i == mapLimit {
+							g.addEllipsis(node, len(keys)-i, path, "map limit reached", mapLimit)
+							break
+						}
+						if g.full() {
+							g.truncate(path, "node limit reached", "limit", g.cfg.NodeLimit, "omitted", len(keys)-i)
+							g.omitted += 2 * (len(keys) - i)
+							break
+						}
+						i += 1
+						g.addMapEntry(node, k, v.MapIndex(k), depth, limit, path)
 					}
-					i += 1
-					kn := g.nextNode()
-					g.AddNode(node, kn, map[string]string{"label": `""`})
-					g.AddEdge(node, kn, true, nil)
-
-					g.addValue(kn, "key", k, depth+1, nil, "<key>")
-
-					kpath := ""
-					switch v.Kind() {
-					case reflect.Array, reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Ptr, reflect.Slice, reflect.Struct, reflect.UnsafePointer:
-						kpath = "k"
-					case reflect.String:
-						kpath = `"` + v.String() + `"`
-					default:
-						kpath = fmt.Sprint(v.Interface())
+					if len(keys) < v.Len() {
+						g.addEllipsis(node, v.Len()-len(keys), path, "map keys not selected", len(keys))
 					}
-					g.addValue(kn, "value", v.MapIndex(k), depth+1, nil, path+"["+kpath+"]")
 				}
-			}
-		case reflect.Ptr:
-			if v.IsNil() {
-				label += ": <nil>"
-			} else {
-				ind := reflect.Indirect(v)
-				params := map[string]string{"style": "dashed"}
-				if n, ok := g.Nodes[ind]; ok {
-					g.AddEdge(node, n, true, params)
+			case reflect.Ptr:
+				if v.IsNil() {
+					label += ": <nil>"
 				} else {
-					g.addValue(node, "", ind, depth, params, path)
+					ind := reflect.Indirect(v)
+					params := map[string]string{"style": "dashed"}
+					if n, ok := g.nodeOf(ind); ok {
+						g.addShared(n, ind.Type())
+						g.addEdge(node, n, params)
+					} else if cut, ok := g.chainCut(v); ok {
+						g.addChainSummary(node, v, cut, depth, limit, path)
+					} else if g.pressure() >= skipPointers {
+						g.truncate(path, "pointer not followed: deadline pressure")
+						label += `\n(deadline: not followed)`
+					} else {
+						g.addPointee(node, "", ind, depth, limit, params, path)
+						if n, ok := g.nodeOf(ind); ok {
+							g.addFinalizerBadge(n, v)
+						}
+					}
 				}
-			}
-		case reflect.Slice:
-			label += `\nslice`
-			if v.IsNil() {
-				label += ": <nil>"
-			} else {
-				l := v.Len()
-				label += fmt.Sprintf(" len: %v cap: %v", l, v.Cap())
-				for i := 0; i < l; i++ {
-					if i == g.cfg.RangeLimit {
-						g.addEllipsis(node, l-i)
+			case reflect.Slice:
+				label += `\nslice`
+				if v.IsNil() {
+					label += ": <nil>"
+				} else {
+					l := v.Len()
+					label += fmt.Sprintf(" len: %v cap: %v", l, v.Cap())
+					g.addSliceView(node, v)
+					if layout, ok := g.byteLayout(path, v); ok {
+						g.debug("decoded with a byte layout", path)
+						g.addByteFields(node, v.Bytes(), layout, path)
+						break
+					}
+					if dump, ok := g.hexdump(v, path); ok {
+						label += dump
+						nodeParams["fontname"] = quote(MonospaceFont)
 						break
 					}
-					idx := "[" + strconv.Itoa(i) + "]"
-					g.addValue(node, idx, v.Index(i), depth+1, nil, path+idx)
+					if s, ok := g.summarized(v, path); ok {
+						label += s
+						break
+					}
+					g.addElements(node, v, depth, limit, path)
 				}
-			}
-		case reflect.Struct:
-			label += `\nstruct`
-			nf := ty.NumField()
-			for i := 0; i < nf; i++ {
-				g.addValue(node, ty.Field(i).Name, v.Field(i), depth+1, nil, path+"."+ty.Field(i).Name)
+			case reflect.Struct:
+				label += `\nstruct`
+				g.findChains(v, plan)
+				label += g.addFields(node, v, plan, depth, limit, path, "", nil)
 			}
 		}
 	} else {
 		label += `\nInvalid`
 	}
 
-	nodeParams["label"] = `"` + label + `"`
-	g.AddNode(parent, node, nodeParams)
+	g.highlight(path, v, nodeParams)
+	label, edgeParams, merged := g.mergeLeaf(node, parent, varName, v, label, label[nameLen:], nodeParams, edgeParams)
+	if merged {
+		return
+	}
+	g.onNode(path, depth, v, label, nodeParams)
+	g.addNode(parent, node, nodeParams)
+	g.addMethods(node, v)
+	if v.IsValid() {
+		if cluster, iface := g.implCluster(v.Type()); cluster != "" {
+			g.addToCluster(cluster, iface.String(), node)
+		}
+	}
+
+	if parent != "G" {
+		g.addEdge(parent, node, edgeParams)
+	}
+}
+
+// addFields adds the fields of the struct v, at path, as children of node. Fields of embedded
+// structs inlined per Config.InlineEmbedded are labeled with prefix if their name is in
+// shadowed. It returns the lines of fields folded into the label of node per
+// Config.CompactScalars, escaped for DOT.
+func (g *Graph) addFields(node string, v reflect.Value, plan *typePlan, depth int, limit int, path string, prefix string, shadowed map[string]bool) string {
+	folded := ""
+	for _, f := range plan.fields {
+		fpath := path + "." + f.name
+		if f.skip {
+			g.debug("field skipped: struct tag", fpath)
+			continue
+		}
+		if g.cfg.SkipUnexported && !f.exported {
+			g.debug("field skipped: SkipUnexported", fpath)
+			continue
+		}
+		fv := v.Field(f.index)
+		if g.hidden(fv) {
+			g.debug("field skipped: HideZero or HideNil", fpath)
+			continue
+		}
+		if !g.keepField(f.name, fpath, fv) {
+			g.debug("field skipped: IncludeFields or ExcludeFields", fpath)
+			continue
+		}
+		label := f.label
+		if shadowed[f.name] {
+			label = prefix + label
+		}
+		tags := g.fieldTags(f)
+		named := label
+		if tags != "" {
+			named += "\n" + tags
+		}
+		switch {
+		case f.redact:
+			g.addLeaf(node, named, fv, fpath, "«redacted»")
+		case f.collapse:
+			g.addLeaf(node, named, fv, fpath, "(collapsed)")
+		case g.inlined(f, fv, fpath):
+			g.debug("embedded struct inlined", fpath)
+			folded += g.addFields(node, fv, planFor(fv.Type()), depth, limit, fpath, prefix+f.label+".", plan.shadow(shadowed))
+		default:
+			if s, ok := g.compactScalar(fv, fpath); ok {
+				g.debug("folded into parent", fpath)
+				if tags != "" {
+					label += " " + tags
+				}
+				folded += escape(label+": ") + s + `\l`
+				continue
+			}
+			g.addValue(node, named, fv, depth+1, limit, nil, fpath)
+		}
+	}
+	if folded != "" && prefix == "" {
+		// Left-justify the folded lines, but not the ones before them.
+		folded = `\n` + folded
+	}
+	return folded
+}
+
+// addMapEntry adds the entry of a map with key k and value val, at path, as a child of node.
+func (g *Graph) addMapEntry(node string, k, val reflect.Value, depth int, limit int, path string) {
+	kn := g.nextNode()
+	g.addNode(node, kn, map[string]string{"label": `""`})
+	g.addEdge(node, kn, nil)
+
+	g.addValue(kn, "key", k, depth+1, limit, nil, "<key>")
+
+	kpath := "k"
+	switch k.Kind() {
+	case reflect.Array, reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Ptr, reflect.Slice, reflect.Struct, reflect.UnsafePointer:
+	default:
+		kpath = g.keyString(k)
+	}
+	g.addValue(kn, "value", val, depth+1, limit, nil, path+"["+kpath+"]")
+}
+
+func (g *Graph) addNode(parent string, name string, attrs map[string]string) {
+	attrs = styleNode(g.cfg.Style, attrs)
+	if g.stream != nil {
+		g.stream.stmt(name, attrs)
+		return
+	}
+	if g.cluster != "" {
+		parent = g.cluster
+	}
+	g.AddNode(parent, name, attrs)
+}
+
+func (g *Graph) addEdge(src string, dst string, attrs map[string]string) {
+	if g.cfg.MergeEqualLeaves {
+		g.hasChildren[src] = true
+	}
+	attrs = styleEdge(g.cfg.Style, g.onEdge(src, dst, attrs))
+	if g.stream != nil {
+		g.stream.edge(src, dst, attrs)
+		return
+	}
+	g.AddEdge(src, dst, true, attrs)
+}
+
+// addLeaf adds a node for v, labeled with its name, type and text, without walking into it.
+func (g *Graph) addLeaf(parent string, varName string, v reflect.Value, path string, text string) {
+	if g.full() {
+		g.truncate(path, "node limit reached", "limit", g.cfg.NodeLimit)
+		g.omitted++
+		return
+	}
+	label := ""
+	if varName != "" {
+		label = varName + "\n"
+	}
+	if v.IsValid() {
+		label += planFor(v.Type()).name + "\n"
+	}
+	g.addChild(parent, map[string]string{
+		"label":   quote(label + text),
+		"shape":   "box",
+		"tooltip": quote(path),
+	})
+}
+
+// cutLabel returns the label of the node of v, not walked into for the given reason, with
+// what's known without doing so: its name and its type or, for interfaces, its dynamic type.
+func cutLabel(varName string, v reflect.Value, reason string) string {
+	label := ""
+	if varName != "" {
+		label = varName + "\n"
+	}
+	switch {
+	case !v.IsValid():
+		label += "Invalid"
+	case v.Kind() == reflect.Interface && v.IsNil():
+		label += v.Type().String() + ": <nil>"
+	case v.Kind() == reflect.Interface:
+		label += v.Type().String() + " holding " + v.Elem().Type().String()
+	case v.Kind() == reflect.Ptr && v.IsNil():
+		label += v.Type().String() + ": <nil>"
+	default:
+		label += v.Type().String()
+	}
+	return label + "\n" + reason
+}
+
+func (g *Graph) addTruncated(parent string, node string, label string) {
+	g.addNode(parent, node, map[string]string{
+		"label": quote(label),
+		"shape": "box",
+	})
 
 	if parent != "G" {
-		g.AddEdge(parent, node, true, edgeParams)
+		g.addEdge(parent, node, nil)
 	}
 }
 
@@ -245,11 +904,12 @@ func (g *Graph) addLabeledChild(parent string, label string) {
 
 func (g *Graph) addChild(parent string, params map[string]string) {
 	kn := g.nextNode()
-	g.AddNode(parent, kn, params)
-	g.AddEdge(parent, kn, true, nil)
+	g.addNode(parent, kn, params)
+	g.addEdge(parent, kn, nil)
 }
 
-func (g *Graph) addEllipsis(parent string, n int) {
+func (g *Graph) addEllipsis(parent string, n int, path string, reason string, limit int) {
+	g.truncate(path, reason, "limit", limit, "omitted", n)
 	g.addLabeledChild(parent, fmt.Sprintf(`"... %v more"`, n))
 }
 
@@ -262,86 +922,72 @@ func (g *Graph) Dot() string {
 	return g.Graph.String()
 }
 
+func (g *Graph) render(f gographvizutil.Format) (string, error) {
+	if err := g.Err(); err != nil {
+		return "", err
+	}
+	if g.cfg.Renderer != nil {
+		return g.cfg.Renderer.Render(g.Graph, g.cfg.Layout, f)
+	}
+	return gographvizutil.RenderLayout(g.Graph, g.cfg.Layout, f)
+}
+
 // Dot returns the graph in SVG format. It requires the dot command to be available in the system.
 func (g *Graph) SVG() (string, error) {
-	return gographvizutil.Render(g.Graph, gographvizutil.SVG)
+	return g.render(gographvizutil.SVG)
 }
 
 // Dot returns the graph in PNG format. It requires the dot command to be available in the system.
 func (g *Graph) PNG() (string, error) {
-	return gographvizutil.Render(g.Graph, gographvizutil.PNG)
+	return g.render(gographvizutil.PNG)
 }
 
 // Dot returns the graph in GIF format. It requires the dot command to be available in the system.
 func (g *Graph) GIF() (string, error) {
-	return gographvizutil.Render(g.Graph, gographvizutil.GIF)
+	return g.render(gographvizutil.GIF)
+}
+
+// JPEG returns the graph in JPEG format. It requires the dot command to be available in the system.
+func (g *Graph) JPEG() (string, error) {
+	return g.render(gographvizutil.JPEG)
 }
 
 // Dot returns the graph in PDF format. It requires the dot command to be available in the system.
 func (g *Graph) PDF() (string, error) {
-	return gographvizutil.Render(g.Graph, gographvizutil.PDF)
+	return g.render(gographvizutil.PDF)
 }
 
 // Dot returns the graph in PostScript format. It requires the dot command to be available in the system.
 func (g *Graph) PostScript() (string, error) {
-	return gographvizutil.Render(g.Graph, gographvizutil.PostScript)
-}
-
-// OpenSVG is a convenience function for opening a graph visualization of the value in the system SVG visualizer.
-// It is intended for debugging.
-// Uses DefaultConfig.
-func OpenSVG(v interface{}) error {
-	return DefaultConfig.OpenSVG(v)
+	return g.render(gographvizutil.PostScript)
 }
 
-// OpenSVG is a convenience method for opening a graph visualization of the value in the system SVG visualizer.
-// It is intended for debugging.
-func (c *Config) OpenSVG(v interface{}) error {
-	s, err := c.Make(v).SVG()
-	if err != nil {
+// RenderAll writes the graph to dir as valuegraph.dot, plus a valuegraph.<format> file for each
+// of the given formats, rendered with a single run of the dot command.
+// Config.Renderer isn't used.
+func (g *Graph) RenderAll(dir string, formats ...gographvizutil.Format) error {
+	if err := g.Err(); err != nil {
 		return err
 	}
-
-	dir, err := ioutil.TempDir("", "valuegraph")
-	if err != nil {
+	if err := ioutil.WriteFile(filepath.Join(dir, "valuegraph.dot"), []byte(g.Dot()), 0644); err != nil {
 		return err
 	}
-
-	f, err := os.Create(filepath.Join(dir, "valuegraph.svg"))
-	if err != nil {
-		return err
+	if len(formats) == 0 {
+		return nil
 	}
-
-	f.Write([]byte(s))
-	f.Close()
-
-	// From go tool pprof.
-	cmds := browsers()
-	for _, cmd := range cmds {
-		args := strings.Split(cmd, " ")
-		if len(args) == 0 {
-			continue
-		}
-		viewer := exec.Command(args[0], append(args[1:], f.Name())...)
-		viewer.Stderr = os.Stderr
-		if err = viewer.Start(); err == nil {
-			return nil
-		}
+	files := make(map[gographvizutil.Format]string, len(formats))
+	for _, f := range formats {
+		files[f] = filepath.Join(dir, "valuegraph."+string(f))
 	}
-
-	return errors.New("no command to open SVG found; temp file is at " + f.Name())
+	return gographvizutil.RenderFiles(g.Graph, g.cfg.Layout, files)
 }
 
-func browsers() []string {
-	// From go tool pprof.
-	cmds := []string{"chrome", "google-chrome", "firefox"}
-	switch runtime.GOOS {
-	case "darwin":
-		cmds = append(cmds, "/usr/bin/open")
-	case "windows":
-		cmds = append(cmds, "cmd /c start")
-	default:
-		cmds = append(cmds, "xdg-open")
+// Image renders the graph as PNG and decodes it, for compositing into other images.
+// It requires the dot command to be available in the system.
+func (g *Graph) Image() (image.Image, error) {
+	s, err := g.PNG()
+	if err != nil {
+		return nil, err
 	}
-	return cmds
+	return png.Decode(strings.NewReader(s))
 }